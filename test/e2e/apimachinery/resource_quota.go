@@ -651,6 +651,94 @@ var _ = SIGDescribe("ResourceQuota", func() {
 		framework.ExpectNoError(err)
 	})
 
+	ginkgo.It("should charge LimitRange defaults against quota and reject pods whose defaults exceed it.", func() {
+		ginkgo.By("Creating a LimitRange with default requests and limits")
+		limitRange := &v1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "quota-limit-range",
+			},
+			Spec: v1.LimitRangeSpec{
+				Limits: []v1.LimitRangeItem{
+					{
+						Type: v1.LimitTypeContainer,
+						Default: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("500m"),
+							v1.ResourceMemory: resource.MustParse("256Mi"),
+						},
+						DefaultRequest: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("300m"),
+							v1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				},
+			},
+		}
+		_, err := f.ClientSet.CoreV1().LimitRanges(f.Namespace.Name).Create(context.TODO(), limitRange, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Creating a ResourceQuota with room for one defaulted pod")
+		quotaName := "quota-for-defaults"
+		resourceQuota := &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: quotaName,
+			},
+			Spec: v1.ResourceQuotaSpec{
+				Hard: v1.ResourceList{
+					v1.ResourcePods:           resource.MustParse("2"),
+					v1.ResourceRequestsCPU:    resource.MustParse("500m"),
+					v1.ResourceRequestsMemory: resource.MustParse("256Mi"),
+					v1.ResourceLimitsCPU:      resource.MustParse("1"),
+					v1.ResourceLimitsMemory:   resource.MustParse("512Mi"),
+				},
+			},
+		}
+		_, err = createResourceQuota(f.ClientSet, f.Namespace.Name, resourceQuota)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Ensuring resource quota status is calculated")
+		usedResources := v1.ResourceList{}
+		usedResources[v1.ResourcePods] = resource.MustParse("0")
+		usedResources[v1.ResourceRequestsCPU] = resource.MustParse("0")
+		usedResources[v1.ResourceRequestsMemory] = resource.MustParse("0")
+		usedResources[v1.ResourceLimitsCPU] = resource.MustParse("0")
+		usedResources[v1.ResourceLimitsMemory] = resource.MustParse("0")
+		err = waitForResourceQuota(f.ClientSet, f.Namespace.Name, quotaName, usedResources)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Creating a Pod with no resource requirements")
+		pod := newTestPodForQuota(f, "default-charged-pod", v1.ResourceList{}, v1.ResourceList{})
+		pod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Ensuring the defaulted resources are charged against the quota")
+		usedResources[v1.ResourcePods] = resource.MustParse("1")
+		usedResources[v1.ResourceRequestsCPU] = resource.MustParse("300m")
+		usedResources[v1.ResourceRequestsMemory] = resource.MustParse("128Mi")
+		usedResources[v1.ResourceLimitsCPU] = resource.MustParse("500m")
+		usedResources[v1.ResourceLimitsMemory] = resource.MustParse("256Mi")
+		err = waitForResourceQuota(f.ClientSet, f.Namespace.Name, quotaName, usedResources)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Not allowing a second defaulted pod that would exceed remaining quota")
+		// 200m of requests.cpu remain, less than the 300m the defaults add.
+		pod = newTestPodForQuota(f, "default-rejected-pod", v1.ResourceList{}, v1.ResourceList{})
+		_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectError(err)
+
+		ginkgo.By("Deleting the pod")
+		err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Delete(context.TODO(), "default-charged-pod", *metav1.NewDeleteOptions(0))
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Ensuring resource quota status released the pod usage")
+		usedResources[v1.ResourcePods] = resource.MustParse("0")
+		usedResources[v1.ResourceRequestsCPU] = resource.MustParse("0")
+		usedResources[v1.ResourceRequestsMemory] = resource.MustParse("0")
+		usedResources[v1.ResourceLimitsCPU] = resource.MustParse("0")
+		usedResources[v1.ResourceLimitsMemory] = resource.MustParse("0")
+		err = waitForResourceQuota(f.ClientSet, f.Namespace.Name, quotaName, usedResources)
+		framework.ExpectNoError(err)
+	})
+
 	/*
 		Release: v1.16
 		Testname: ResourceQuota, quota scope, Terminating and NotTerminating scope
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apimachinery
+
+import (
+	"context"
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+
+	"github.com/onsi/ginkgo"
+)
+
+// These specs exercise the admissionregistration.k8s.io endpoints on the
+// configuration objects themselves. The webhooks never run: they point at an
+// unresolvable URL, fail open and only match objects labeled with a random
+// UUID, so no backend deployment is needed, unlike the functional webhook
+// tests.
+var _ = SIGDescribe("Webhook configuration objects", func() {
+	f := framework.NewDefaultFramework("webhook-configurations")
+
+	ginkgo.It("should support validating webhook configuration create, list, patch, update and deleteCollection", func() {
+		client := f.ClientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+		testUUID := string(uuid.NewUUID())
+		selector := metav1.ListOptions{LabelSelector: "e2e-webhook-configurations-uuid=" + testUUID}
+
+		ginkgo.By("Creating validating webhook configurations")
+		for i := 0; i < 3; i++ {
+			config := &admissionregistrationv1.ValidatingWebhookConfiguration{
+				ObjectMeta: inertWebhookConfigurationMeta(fmt.Sprintf("%s-%d", f.UniqueName, i), testUUID),
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{
+					{
+						Name:                    "inert.validating.e2e.example.com",
+						Rules:                   inertWebhookRules(),
+						ClientConfig:            inertWebhookClientConfig(),
+						FailurePolicy:           inertFailurePolicy(),
+						NamespaceSelector:       inertNamespaceSelector(testUUID),
+						SideEffects:             inertSideEffects(),
+						AdmissionReviewVersions: []string{"v1", "v1beta1"},
+					},
+				},
+			}
+			_, err := client.Create(context.TODO(), config, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "creating validating webhook configuration %s", config.Name)
+		}
+
+		ginkgo.By("Listing the configurations with a label selector")
+		list, err := client.List(context.TODO(), selector)
+		framework.ExpectNoError(err, "listing validating webhook configurations")
+		framework.ExpectEqual(len(list.Items), 3)
+
+		ginkgo.By("Patching a configuration's rules")
+		patched, err := client.Patch(context.TODO(), list.Items[0].Name, types.StrategicMergePatchType,
+			[]byte(`{"metadata":{"labels":{"e2e-patched":"true"}}}`), metav1.PatchOptions{})
+		framework.ExpectNoError(err, "patching validating webhook configuration")
+		framework.ExpectEqual(patched.Labels["e2e-patched"], "true")
+
+		ginkgo.By("Updating a configuration's namespaceSelector")
+		toUpdate, err := client.Get(context.TODO(), list.Items[1].Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "getting validating webhook configuration")
+		toUpdate.Webhooks[0].NamespaceSelector.MatchLabels["e2e-updated"] = "true"
+		updated, err := client.Update(context.TODO(), toUpdate, metav1.UpdateOptions{})
+		framework.ExpectNoError(err, "updating validating webhook configuration")
+		framework.ExpectEqual(updated.Webhooks[0].NamespaceSelector.MatchLabels["e2e-updated"], "true")
+
+		ginkgo.By("Deleting the collection of configurations")
+		err = client.DeleteCollection(context.TODO(), metav1.DeleteOptions{}, selector)
+		framework.ExpectNoError(err, "deleting collection of validating webhook configurations")
+		list, err = client.List(context.TODO(), selector)
+		framework.ExpectNoError(err, "listing validating webhook configurations after deleteCollection")
+		framework.ExpectEqual(len(list.Items), 0)
+	})
+
+	ginkgo.It("should support mutating webhook configuration create, list, patch, update and deleteCollection", func() {
+		client := f.ClientSet.AdmissionregistrationV1().MutatingWebhookConfigurations()
+		testUUID := string(uuid.NewUUID())
+		selector := metav1.ListOptions{LabelSelector: "e2e-webhook-configurations-uuid=" + testUUID}
+
+		ginkgo.By("Creating mutating webhook configurations")
+		for i := 0; i < 3; i++ {
+			config := &admissionregistrationv1.MutatingWebhookConfiguration{
+				ObjectMeta: inertWebhookConfigurationMeta(fmt.Sprintf("%s-%d", f.UniqueName, i), testUUID),
+				Webhooks: []admissionregistrationv1.MutatingWebhook{
+					{
+						Name:                    "inert.mutating.e2e.example.com",
+						Rules:                   inertWebhookRules(),
+						ClientConfig:            inertWebhookClientConfig(),
+						FailurePolicy:           inertFailurePolicy(),
+						NamespaceSelector:       inertNamespaceSelector(testUUID),
+						SideEffects:             inertSideEffects(),
+						AdmissionReviewVersions: []string{"v1", "v1beta1"},
+					},
+				},
+			}
+			_, err := client.Create(context.TODO(), config, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "creating mutating webhook configuration %s", config.Name)
+		}
+
+		ginkgo.By("Listing the configurations with a label selector")
+		list, err := client.List(context.TODO(), selector)
+		framework.ExpectNoError(err, "listing mutating webhook configurations")
+		framework.ExpectEqual(len(list.Items), 3)
+
+		ginkgo.By("Patching a configuration's rules")
+		patched, err := client.Patch(context.TODO(), list.Items[0].Name, types.StrategicMergePatchType,
+			[]byte(`{"metadata":{"labels":{"e2e-patched":"true"}}}`), metav1.PatchOptions{})
+		framework.ExpectNoError(err, "patching mutating webhook configuration")
+		framework.ExpectEqual(patched.Labels["e2e-patched"], "true")
+
+		ginkgo.By("Updating a configuration's namespaceSelector")
+		toUpdate, err := client.Get(context.TODO(), list.Items[1].Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "getting mutating webhook configuration")
+		toUpdate.Webhooks[0].NamespaceSelector.MatchLabels["e2e-updated"] = "true"
+		updated, err := client.Update(context.TODO(), toUpdate, metav1.UpdateOptions{})
+		framework.ExpectNoError(err, "updating mutating webhook configuration")
+		framework.ExpectEqual(updated.Webhooks[0].NamespaceSelector.MatchLabels["e2e-updated"], "true")
+
+		ginkgo.By("Deleting the collection of configurations")
+		err = client.DeleteCollection(context.TODO(), metav1.DeleteOptions{}, selector)
+		framework.ExpectNoError(err, "deleting collection of mutating webhook configurations")
+		list, err = client.List(context.TODO(), selector)
+		framework.ExpectNoError(err, "listing mutating webhook configurations after deleteCollection")
+		framework.ExpectEqual(len(list.Items), 0)
+	})
+
+	ginkgo.AfterEach(func() {
+		cleanWebhookConfigurationsByLabel(f.ClientSet, "e2e-webhook-configurations-uuid")
+	})
+})
+
+func inertWebhookConfigurationMeta(name, testUUID string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{"e2e-webhook-configurations-uuid": testUUID},
+	}
+}
+
+// inertWebhookRules matches only configmaps, which combined with the
+// namespace selector below never selects a real object.
+func inertWebhookRules() []admissionregistrationv1.RuleWithOperations {
+	return []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"configmaps"},
+			},
+		},
+	}
+}
+
+// inertWebhookClientConfig points at an unresolvable URL; the webhook is
+// never expected to be called.
+func inertWebhookClientConfig() admissionregistrationv1.WebhookClientConfig {
+	return admissionregistrationv1.WebhookClientConfig{
+		URL: strPtr("https://webhook.inert.e2e.invalid/never-called"),
+	}
+}
+
+func inertFailurePolicy() *admissionregistrationv1.FailurePolicyType {
+	policy := admissionregistrationv1.Ignore
+	return &policy
+}
+
+// inertNamespaceSelector only matches namespaces labeled with the test's
+// UUID, which no namespace carries.
+func inertNamespaceSelector(testUUID string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{"e2e-webhook-configurations-ns-uuid": testUUID},
+	}
+}
+
+func inertSideEffects() *admissionregistrationv1.SideEffectClass {
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	return &sideEffects
+}
+
+// cleanWebhookConfigurationsByLabel removes any configurations a failed spec
+// left behind.
+func cleanWebhookConfigurationsByLabel(client clientset.Interface, labelKey string) {
+	listOpts := metav1.ListOptions{LabelSelector: labelKey}
+	if err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().DeleteCollection(context.TODO(), metav1.DeleteOptions{}, listOpts); err != nil {
+		framework.Logf("Failed to clean up validating webhook configurations: %v", err)
+	}
+	if err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().DeleteCollection(context.TODO(), metav1.DeleteOptions{}, listOpts); err != nil {
+		framework.Logf("Failed to clean up mutating webhook configurations: %v", err)
+	}
+}
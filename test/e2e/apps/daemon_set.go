@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
@@ -96,6 +97,7 @@ func updateDaemonSetWithRetries(c clientset.Interface, namespace, name string, a
 // See http://issues.k8s.io/21767 for more details
 var _ = SIGDescribe("Daemon set [Serial]", func() {
 	var f *framework.Framework
+	var nodeLabelLock *e2enode.LabelLock
 
 	ginkgo.AfterEach(func() {
 		// Clean up
@@ -121,6 +123,10 @@ var _ = SIGDescribe("Daemon set [Serial]", func() {
 		}
 		err = clearDaemonSetNodeLabels(f.ClientSet)
 		framework.ExpectNoError(err)
+		if nodeLabelLock != nil {
+			framework.ExpectNoError(nodeLabelLock.Release())
+			nodeLabelLock = nil
+		}
 	})
 
 	f = framework.NewDefaultFramework("daemonsets")
@@ -141,6 +147,11 @@ var _ = SIGDescribe("Daemon set [Serial]", func() {
 
 		ns = updatedNS.Name
 
+		// Guard this suite's node label mutations against other processes
+		// labeling nodes under the same prefix.
+		nodeLabelLock, err = e2enode.AcquireLabelLock(c, daemonsetLabelPrefix)
+		framework.ExpectNoError(err)
+
 		err = clearDaemonSetNodeLabels(c)
 		framework.ExpectNoError(err)
 	})
@@ -322,7 +333,7 @@ var _ = SIGDescribe("Daemon set [Serial]", func() {
 		// Check history and labels
 		ds, err = c.AppsV1().DaemonSets(ns).Get(context.TODO(), ds.Name, metav1.GetOptions{})
 		framework.ExpectNoError(err)
-		waitForHistoryCreated(c, ns, label, 1)
+		waitForHistoryCreated(f.DynamicClient, ns, label, 1)
 		first := curHistory(listDaemonHistories(c, ns, label), ds)
 		firstHash := first.Labels[appsv1.DefaultDaemonSetUniqueLabelKey]
 		framework.ExpectEqual(first.Revision, int64(1))
@@ -344,13 +355,88 @@ var _ = SIGDescribe("Daemon set [Serial]", func() {
 		// Check history and labels
 		ds, err = c.AppsV1().DaemonSets(ns).Get(context.TODO(), ds.Name, metav1.GetOptions{})
 		framework.ExpectNoError(err)
-		waitForHistoryCreated(c, ns, label, 2)
+		waitForHistoryCreated(f.DynamicClient, ns, label, 2)
 		cur := curHistory(listDaemonHistories(c, ns, label), ds)
 		framework.ExpectEqual(cur.Revision, int64(2))
 		framework.ExpectNotEqual(cur.Labels[appsv1.DefaultDaemonSetUniqueLabelKey], firstHash)
 		checkDaemonSetPodsLabels(listDaemonPods(c, ns, label), firstHash)
 	})
 
+	ginkgo.It("should adopt existing pods and history when recreated with the same selector", func() {
+		label := map[string]string{daemonsetNameLabel: dsName}
+
+		ginkgo.By(fmt.Sprintf("Creating a simple DaemonSet %q", dsName))
+		ds, err := c.AppsV1().DaemonSets(ns).Create(context.TODO(), newDaemonSet(dsName, image, label), metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Check that daemon pods launch on every node of the cluster.")
+		err = wait.PollImmediate(dsRetryPeriod, dsRetryTimeout, checkRunningOnAllNodes(f, ds))
+		framework.ExpectNoError(err, "error waiting for daemon pod to start")
+
+		// Record the current history and pod UIDs before orphaning.
+		ds, err = c.AppsV1().DaemonSets(ns).Get(context.TODO(), ds.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		waitForHistoryCreated(f.DynamicClient, ns, label, 1)
+		first := curHistory(listDaemonHistories(c, ns, label), ds)
+		firstHash := first.Labels[appsv1.DefaultDaemonSetUniqueLabelKey]
+		framework.ExpectEqual(first.Revision, int64(1))
+		podUIDs := make(map[types.UID]string)
+		for _, pod := range listDaemonPods(c, ns, label).Items {
+			podUIDs[pod.UID] = pod.Name
+		}
+
+		ginkgo.By("Deleting the DaemonSet while orphaning its pods")
+		orphan := metav1.DeletePropagationOrphan
+		err = c.AppsV1().DaemonSets(ns).Delete(context.TODO(), dsName, metav1.DeleteOptions{PropagationPolicy: &orphan})
+		framework.ExpectNoError(err)
+		err = wait.PollImmediate(dsRetryPeriod, dsRetryTimeout, func() (bool, error) {
+			_, err := c.AppsV1().DaemonSets(ns).Get(context.TODO(), dsName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		framework.ExpectNoError(err, "error waiting for the orphaned DaemonSet to be deleted")
+
+		ginkgo.By("Check that the daemon pods are released")
+		err = wait.PollImmediate(dsRetryPeriod, dsRetryTimeout, func() (bool, error) {
+			for _, pod := range listDaemonPods(c, ns, label).Items {
+				if metav1.GetControllerOf(&pod) != nil {
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+		framework.ExpectNoError(err, "error waiting for daemon pods to be orphaned")
+
+		ginkgo.By("Recreating the DaemonSet with the same selector")
+		ds, err = c.AppsV1().DaemonSets(ns).Create(context.TODO(), newDaemonSet(dsName, image, label), metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Check that the existing pods are adopted")
+		err = wait.PollImmediate(dsRetryPeriod, dsRetryTimeout, func() (bool, error) {
+			for _, pod := range listDaemonPods(c, ns, label).Items {
+				controllerRef := metav1.GetControllerOf(&pod)
+				if controllerRef == nil || controllerRef.UID != ds.UID {
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+		framework.ExpectNoError(err, "error waiting for daemon pods to be adopted")
+		for _, pod := range listDaemonPods(c, ns, label).Items {
+			if _, ok := podUIDs[pod.UID]; !ok {
+				framework.Failf("Pod %q was recreated instead of adopted", pod.Name)
+			}
+		}
+
+		ginkgo.By("Check that the existing ControllerRevision is reused")
+		waitForHistoryCreated(f.DynamicClient, ns, label, 1)
+		cur := curHistory(listDaemonHistories(c, ns, label), ds)
+		framework.ExpectEqual(cur.Labels[appsv1.DefaultDaemonSetUniqueLabelKey], firstHash, "expected the recreated DaemonSet to reuse the existing history")
+		framework.ExpectEqual(cur.Revision, int64(1))
+	})
+
 	/*
 	  Testname: DaemonSet-RollingUpdate
 	  Description: A conformant Kubernetes distribution MUST support DaemonSet RollingUpdates.
@@ -371,7 +457,7 @@ var _ = SIGDescribe("Daemon set [Serial]", func() {
 		// Check history and labels
 		ds, err = c.AppsV1().DaemonSets(ns).Get(context.TODO(), ds.Name, metav1.GetOptions{})
 		framework.ExpectNoError(err)
-		waitForHistoryCreated(c, ns, label, 1)
+		waitForHistoryCreated(f.DynamicClient, ns, label, 1)
 		cur := curHistory(listDaemonHistories(c, ns, label), ds)
 		hash := cur.Labels[appsv1.DefaultDaemonSetUniqueLabelKey]
 		framework.ExpectEqual(cur.Revision, int64(1))
@@ -400,7 +486,7 @@ var _ = SIGDescribe("Daemon set [Serial]", func() {
 		// Check history and labels
 		ds, err = c.AppsV1().DaemonSets(ns).Get(context.TODO(), ds.Name, metav1.GetOptions{})
 		framework.ExpectNoError(err)
-		waitForHistoryCreated(c, ns, label, 2)
+		waitForHistoryCreated(f.DynamicClient, ns, label, 2)
 		cur = curHistory(listDaemonHistories(c, ns, label), ds)
 		hash = cur.Labels[appsv1.DefaultDaemonSetUniqueLabelKey]
 		framework.ExpectEqual(cur.Revision, int64(2))
@@ -756,21 +842,10 @@ func checkDaemonSetPodsLabels(podList *v1.PodList, hash string) {
 	}
 }
 
-func waitForHistoryCreated(c clientset.Interface, ns string, label map[string]string, numHistory int) {
-	listHistoryFn := func() (bool, error) {
-		selector := labels.Set(label).AsSelector()
-		options := metav1.ListOptions{LabelSelector: selector.String()}
-		historyList, err := c.AppsV1().ControllerRevisions(ns).List(context.TODO(), options)
-		if err != nil {
-			return false, err
-		}
-		if len(historyList.Items) == numHistory {
-			return true, nil
-		}
-		framework.Logf("%d/%d controllerrevisions created.", len(historyList.Items), numHistory)
-		return false, nil
-	}
-	err := wait.PollImmediate(dsRetryPeriod, dsRetryTimeout, listHistoryFn)
+func waitForHistoryCreated(dc dynamic.Interface, ns string, label map[string]string, numHistory int) {
+	gvr := appsv1.SchemeGroupVersion.WithResource("controllerrevisions")
+	selector := labels.Set(label).AsSelector().String()
+	err := framework.WaitForObjectCount(dc, gvr, ns, selector, numHistory, dsRetryTimeout)
 	framework.ExpectNoError(err, "error waiting for controllerrevisions to be created")
 }
 
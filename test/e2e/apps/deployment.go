@@ -116,6 +116,15 @@ var _ = SIGDescribe("Deployment", func() {
 	ginkgo.It("iterative rollouts should eventually progress", func() {
 		testIterativeDeployments(f)
 	})
+	ginkgo.It("should report ProgressDeadlineExceeded for a wedged rollout and recover when fixed", func() {
+		testProgressDeadlineExceeded(f)
+	})
+	ginkgo.It("paused deployment should not create new replica sets and should batch changes on resume", func() {
+		testPausedDeployment(f)
+	})
+	ginkgo.It("should roll back by applying a previous pod template", func() {
+		testRollbackDeploymentWithTemplate(f)
+	})
 	ginkgo.It("test Deployment ReplicaSet orphaning and adoption regarding controllerRef", func() {
 		testDeploymentsControllerRef(f)
 	})
@@ -640,6 +649,194 @@ func testIterativeDeployments(f *framework.Framework) {
 	framework.ExpectNoError(err)
 }
 
+// testPausedDeployment verifies that a paused deployment does not realize any state,
+// and that template changes made while paused are batched into one rollout on resume.
+func testPausedDeployment(f *framework.Framework) {
+	ns := f.Namespace.Name
+	c := f.ClientSet
+
+	deploymentName := "test-paused-deployment"
+	podLabels := map[string]string{"name": WebserverImageName}
+	replicas := int32(1)
+	tgps := int64(1)
+	d := e2edeployment.NewDeployment(deploymentName, replicas, podLabels, WebserverImageName, WebserverImage, appsv1.RollingUpdateDeploymentStrategyType)
+	d.Spec.Paused = true
+	d.Spec.Template.Spec.TerminationGracePeriodSeconds = &tgps
+
+	framework.Logf("Creating paused deployment %q", deploymentName)
+	deployment, err := c.AppsV1().Deployments(ns).Create(context.TODO(), d, metav1.CreateOptions{})
+	framework.ExpectNoError(err)
+	err = waitForObservedDeployment(c, ns, deploymentName, deployment.Generation)
+	framework.ExpectNoError(err)
+
+	// Verify that no replica set was created while the deployment is paused.
+	rs, err := deploymentutil.GetNewReplicaSet(deployment, c.AppsV1())
+	framework.ExpectNoError(err)
+	if rs != nil {
+		framework.Failf("Paused deployment %q created replica set %q", deploymentName, rs.Name)
+	}
+
+	framework.Logf("Resuming deployment %q", deploymentName)
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		update.Spec.Paused = false
+	})
+	framework.ExpectNoError(err)
+	err = e2edeployment.WaitForDeploymentComplete(c, deployment)
+	framework.ExpectNoError(err)
+	err = checkDeploymentRevisionAndImage(c, ns, deploymentName, "1", WebserverImage)
+	framework.ExpectNoError(err)
+
+	framework.Logf("Pausing deployment %q before updating its template", deploymentName)
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		update.Spec.Paused = true
+	})
+	framework.ExpectNoError(err)
+
+	// Make two separate template changes while paused; neither may be rolled out yet.
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		newEnv := v1.EnvVar{Name: "PAUSED_CHANGE", Value: "batched"}
+		update.Spec.Template.Spec.Containers[0].Env = append(update.Spec.Template.Spec.Containers[0].Env, newEnv)
+	})
+	framework.ExpectNoError(err)
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		update.Spec.Template.Spec.Containers[0].Image = NewWebserverImage
+	})
+	framework.ExpectNoError(err)
+	err = waitForObservedDeployment(c, ns, deploymentName, deployment.Generation)
+	framework.ExpectNoError(err)
+
+	// Verify that no new replica set shows up by waiting for one with a timeout and
+	// asserting that we hit the timeout.
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	framework.ExpectNoError(err)
+	opts := metav1.ListOptions{LabelSelector: selector.String()}
+	waitErr := wait.PollImmediate(poll, 30*time.Second, func() (bool, error) {
+		rsList, err := c.AppsV1().ReplicaSets(ns).List(context.TODO(), opts)
+		if err != nil {
+			return false, err
+		}
+		return len(rsList.Items) != 1, nil
+	})
+	if waitErr != wait.ErrWaitTimeout {
+		framework.ExpectNoError(waitErr)
+		framework.Failf("Paused deployment %q created a new replica set for an updated template", deploymentName)
+	}
+
+	framework.Logf("Resuming deployment %q to roll out the batched changes", deploymentName)
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		update.Spec.Paused = false
+	})
+	framework.ExpectNoError(err)
+
+	// Both changes must be batched into a single new rollout, i.e. revision 2.
+	err = e2edeployment.WaitForDeploymentRevisionAndImage(c, ns, deploymentName, "2", NewWebserverImage)
+	framework.ExpectNoError(err)
+	err = e2edeployment.WaitForDeploymentComplete(c, deployment)
+	framework.ExpectNoError(err)
+
+	rsList, err := c.AppsV1().ReplicaSets(ns).List(context.TODO(), opts)
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(len(rsList.Items), 2, "expected the batched changes to create exactly one new replica set")
+}
+
+// testRollbackDeploymentWithTemplate rolls a deployment back by re-applying a previous
+// pod template and verifies that the old replica set is reused with a bumped revision.
+func testRollbackDeploymentWithTemplate(f *framework.Framework) {
+	ns := f.Namespace.Name
+	c := f.ClientSet
+
+	deploymentName := "test-rollback-deployment"
+	podLabels := map[string]string{"name": WebserverImageName}
+	replicas := int32(1)
+
+	framework.Logf("Creating deployment %q", deploymentName)
+	d := e2edeployment.NewDeployment(deploymentName, replicas, podLabels, WebserverImageName, WebserverImage, appsv1.RollingUpdateDeploymentStrategyType)
+	deployment, err := c.AppsV1().Deployments(ns).Create(context.TODO(), d, metav1.CreateOptions{})
+	framework.ExpectNoError(err)
+
+	// Wait for revision 1.
+	err = e2edeployment.WaitForDeploymentComplete(c, deployment)
+	framework.ExpectNoError(err)
+	err = checkDeploymentRevisionAndImage(c, ns, deploymentName, "1", WebserverImage)
+	framework.ExpectNoError(err)
+
+	deployment, err = c.AppsV1().Deployments(ns).Get(context.TODO(), deploymentName, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	firstRS, err := deploymentutil.GetNewReplicaSet(deployment, c.AppsV1())
+	framework.ExpectNoError(err)
+
+	// Roll out revision 2 with a new image.
+	framework.Logf("Updating deployment %q with a new image", deploymentName)
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		update.Spec.Template.Spec.Containers[0].Image = NewWebserverImage
+	})
+	framework.ExpectNoError(err)
+	err = e2edeployment.WaitForDeploymentRevisionAndImage(c, ns, deploymentName, "2", NewWebserverImage)
+	framework.ExpectNoError(err)
+	err = e2edeployment.WaitForDeploymentComplete(c, deployment)
+	framework.ExpectNoError(err)
+
+	// Roll back by re-applying the revision 1 template.
+	framework.Logf("Rolling deployment %q back by applying the previous template", deploymentName)
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		update.Spec.Template.Spec.Containers[0].Image = WebserverImage
+	})
+	framework.ExpectNoError(err)
+
+	// The old replica set is reused and its revision annotation is bumped to 3.
+	err = e2edeployment.WaitForDeploymentRevisionAndImage(c, ns, deploymentName, "3", WebserverImage)
+	framework.ExpectNoError(err)
+	err = e2edeployment.WaitForDeploymentComplete(c, deployment)
+	framework.ExpectNoError(err)
+
+	deployment, err = c.AppsV1().Deployments(ns).Get(context.TODO(), deploymentName, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	newRS, err := deploymentutil.GetNewReplicaSet(deployment, c.AppsV1())
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(newRS.Name, firstRS.Name, "expected the rollback to reuse the first replica set")
+}
+
+// testProgressDeadlineExceeded wedges a rollout with a non-existent image and verifies
+// that the Progressing condition times out at progressDeadlineSeconds, then fixes the
+// image and verifies the condition recovers.
+func testProgressDeadlineExceeded(f *framework.Framework) {
+	ns := f.Namespace.Name
+	c := f.ClientSet
+
+	podLabels := map[string]string{"name": WebserverImageName}
+	replicas := int32(1)
+	deadline := int32(30)
+	zero := int64(0)
+
+	// Create a deployment with a bad image so that the rollout can never progress.
+	deploymentName := "progress-deadline"
+	framework.Logf("Creating deployment %q with progressDeadlineSeconds set to %ds and a non-existent image", deploymentName, deadline)
+	d := e2edeployment.NewDeployment(deploymentName, replicas, podLabels, WebserverImageName, "webserver:404", appsv1.RollingUpdateDeploymentStrategyType)
+	d.Spec.ProgressDeadlineSeconds = &deadline
+	d.Spec.Template.Spec.TerminationGracePeriodSeconds = &zero
+	deployment, err := c.AppsV1().Deployments(ns).Create(context.TODO(), d, metav1.CreateOptions{})
+	framework.ExpectNoError(err)
+
+	framework.Logf("Watching deployment %q for the Progressing condition to report %q", deploymentName, deploymentutil.TimedOutReason)
+	err = watchDeploymentProgressingCondition(c, deployment, deploymentutil.TimedOutReason, time.Duration(deadline)*time.Second+pollLongTimeout)
+	framework.ExpectNoError(err)
+
+	// Fix the image and verify that the rollout completes and the condition recovers.
+	framework.Logf("Updating deployment %q with a working image", deploymentName)
+	deployment, err = e2edeployment.UpdateDeploymentWithRetries(c, ns, deploymentName, func(update *appsv1.Deployment) {
+		update.Spec.Template.Spec.Containers[0].Image = WebserverImage
+	})
+	framework.ExpectNoError(err)
+
+	framework.Logf("Watching deployment %q for the Progressing condition to report %q", deploymentName, deploymentutil.NewRSAvailableReason)
+	err = watchDeploymentProgressingCondition(c, deployment, deploymentutil.NewRSAvailableReason, pollLongTimeout)
+	framework.ExpectNoError(err)
+
+	framework.Logf("Waiting for deployment %q status", deploymentName)
+	err = e2edeployment.WaitForDeploymentComplete(c, deployment)
+	framework.ExpectNoError(err)
+}
+
 func testDeploymentsControllerRef(f *framework.Framework) {
 	ns := f.Namespace.Name
 	c := f.ClientSet
@@ -1065,6 +1262,31 @@ func watchRecreateDeployment(c clientset.Interface, d *appsv1.Deployment) error
 	return err
 }
 
+// watchDeploymentProgressingCondition watches the deployment from its current
+// resourceVersion until the Progressing condition reports the given reason.
+func watchDeploymentProgressingCondition(c clientset.Interface, d *appsv1.Deployment, reason string, timeout time.Duration) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", d.Name).String()
+	w := &cache.ListWatch{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return c.AppsV1().Deployments(d.Namespace).Watch(context.TODO(), options)
+		},
+	}
+
+	var cond *appsv1.DeploymentCondition
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_, err := watchtools.Until(ctx, d.ResourceVersion, w, func(event watch.Event) (bool, error) {
+		deployment := event.Object.(*appsv1.Deployment)
+		cond = deploymentutil.GetDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
+		return cond != nil && cond.Reason == reason, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		err = fmt.Errorf("deployment %q never had a Progressing condition with reason %q, last condition: %+v", d.Name, reason, cond)
+	}
+	return err
+}
+
 // waitForDeploymentOldRSsNum waits for the deployment to clean up old rcs.
 func waitForDeploymentOldRSsNum(c clientset.Interface, ns, deploymentName string, desiredRSNum int) error {
 	var oldRSs []*appsv1.ReplicaSet
@@ -339,4 +339,19 @@ func testRSAdoptMatchingAndReleaseNotMatching(f *framework.Framework) {
 		return true, nil
 	})
 	framework.ExpectNoError(err)
+
+	ginkgo.By("When the released pods are deleted as a collection")
+	orphanSelector := labels.SelectorFromSet(labels.Set(map[string]string{"name": "not-matching-name"})).String()
+	err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: orphanSelector})
+	framework.ExpectNoError(err, "failed to delete orphaned pods as a collection")
+
+	ginkgo.By("Then the released pods are removed")
+	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		orphans, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).List(context.TODO(), metav1.ListOptions{LabelSelector: orphanSelector})
+		if err != nil {
+			return false, err
+		}
+		return len(orphans.Items) == 0, nil
+	})
+	framework.ExpectNoError(err, "released pods were not deleted by deleteCollection")
 }
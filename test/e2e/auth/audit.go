@@ -21,7 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -30,12 +29,11 @@ import (
 	"k8s.io/apiextensions-apiserver/test/integration/fixtures"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
 	auditinternal "k8s.io/apiserver/pkg/apis/audit"
-	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2eauditlog "k8s.io/kubernetes/test/e2e/framework/auditlog"
 	e2eauth "k8s.io/kubernetes/test/e2e/framework/auth"
 	e2edeployment "k8s.io/kubernetes/test/e2e/framework/deployment"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
@@ -730,22 +728,6 @@ var _ = SIGDescribe("Advanced Audit [DisabledForLargeClusters][Flaky]", func() {
 func expectEvents(f *framework.Framework, expectedEvents []utils.AuditEvent) {
 	// The default flush timeout is 30 seconds, therefore it should be enough to retry once
 	// to find all expected events. However, we're waiting for 5 minutes to avoid flakes.
-	pollingInterval := 30 * time.Second
-	pollingTimeout := 5 * time.Minute
-	err := wait.Poll(pollingInterval, pollingTimeout, func() (bool, error) {
-		// Fetch the log stream.
-		stream, err := f.ClientSet.CoreV1().RESTClient().Get().AbsPath("/logs/kube-apiserver-audit.log").Stream(context.TODO())
-		if err != nil {
-			return false, err
-		}
-		defer stream.Close()
-		missingReport, err := utils.CheckAuditLines(stream, expectedEvents, auditv1.SchemeGroupVersion)
-		if err != nil {
-			framework.Logf("Failed to observe audit events: %v", err)
-		} else if len(missingReport.MissingEvents) > 0 {
-			framework.Logf(missingReport.String())
-		}
-		return len(missingReport.MissingEvents) == 0, nil
-	})
-	framework.ExpectNoError(err, "after %v failed to observe audit events", pollingTimeout)
+	checker := e2eauditlog.NewChecker(e2eauditlog.NewAPIServerLogSource(f.ClientSet, "kube-apiserver-audit.log"))
+	checker.ExpectEvents(expectedEvents)
 }
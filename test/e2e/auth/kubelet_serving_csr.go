@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	v1beta1client "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+
+	"github.com/onsi/ginkgo"
+)
+
+var _ = SIGDescribe("[Feature:KubeletServingCertificateRotation] Kubelet serving certificate", func() {
+	f := framework.NewDefaultFramework("kubelet-serving-csr")
+
+	// The test only makes sense when the kubelets bootstrap their serving
+	// certificates through the CSR API, i.e. they run with serverTLSBootstrap
+	// enabled. That configuration is detected by the presence of CSRs for the
+	// kubelet-serving signer.
+	ginkgo.It("should serve the kubelet endpoints with a certificate signed by the cluster CA once its CSR is approved", func() {
+		csrs := f.ClientSet.CertificatesV1beta1().CertificateSigningRequests()
+
+		csrList, err := csrs.List(context.TODO(), metav1.ListOptions{})
+		framework.ExpectNoError(err)
+		servingCSRSeen := false
+		for i := range csrList.Items {
+			csr := &csrList.Items[i]
+			if csr.Spec.SignerName == nil || *csr.Spec.SignerName != certificatesv1beta1.KubeletServingSignerName {
+				continue
+			}
+			servingCSRSeen = true
+			if isPendingCSR(csr) {
+				approveCSR(csrs, csr)
+			}
+		}
+		if !servingCSRSeen {
+			e2eskipper.Skipf("no %s CSRs found; kubelet serving certificate rotation is not enabled", certificatesv1beta1.KubeletServingSignerName)
+		}
+
+		node, err := e2enode.GetRandomReadySchedulableNode(f.ClientSet)
+		framework.ExpectNoError(err)
+		ips := e2enode.GetAddresses(node, v1.NodeInternalIP)
+		if len(ips) == 0 {
+			e2eskipper.Skipf("node %s has no internal IP to dial the kubelet on", node.Name)
+		}
+		host := net.JoinHostPort(ips[0], fmt.Sprintf("%d", framework.KubeletPort))
+
+		rcfg, err := framework.LoadConfig()
+		framework.ExpectNoError(err)
+		caData := rcfg.TLSClientConfig.CAData
+		if len(caData) == 0 && rcfg.TLSClientConfig.CAFile != "" {
+			caData, err = ioutil.ReadFile(rcfg.TLSClientConfig.CAFile)
+			framework.ExpectNoError(err)
+		}
+		if len(caData) == 0 {
+			e2eskipper.Skipf("no cluster CA available in the client config to verify the kubelet serving certificate against")
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caData) {
+			framework.Failf("failed to parse cluster CA bundle")
+		}
+
+		client := &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: caPool},
+			},
+		}
+
+		// The kubelet only picks up the signed certificate asynchronously, so
+		// retry verification failures until rotation has happened.
+		for _, path := range []string{"/metrics", "/logs/"} {
+			url := fmt.Sprintf("https://%s%s", host, path)
+			ginkgo.By(fmt.Sprintf("verifying %s is served with a certificate signed by the cluster CA", url))
+			framework.ExpectNoError(wait.Poll(10*time.Second, 5*time.Minute, func() (bool, error) {
+				req, err := http.NewRequest("GET", url, nil)
+				if err != nil {
+					return false, err
+				}
+				req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", rcfg.BearerToken))
+				resp, err := client.Do(req)
+				if err != nil {
+					framework.Logf("request to %s failed: %v", url, err)
+					return false, nil
+				}
+				defer resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					framework.Logf("request to %s returned status %d", url, resp.StatusCode)
+					return false, nil
+				}
+				return true, nil
+			}), "kubelet on node %s never served %s with a certificate verifiable against the cluster CA", node.Name, path)
+		}
+	})
+})
+
+// isPendingCSR returns true if the CSR has been neither approved nor denied.
+func isPendingCSR(csr *certificatesv1beta1.CertificateSigningRequest) bool {
+	for _, c := range csr.Status.Conditions {
+		if c.Type == certificatesv1beta1.CertificateApproved || c.Type == certificatesv1beta1.CertificateDenied {
+			return false
+		}
+	}
+	return true
+}
+
+// approveCSR marks the given CSR approved and waits for it to be signed.
+func approveCSR(csrs v1beta1client.CertificateSigningRequestInterface, csr *certificatesv1beta1.CertificateSigningRequest) {
+	csrName := csr.Name
+	framework.Logf("approving CSR %q", csrName)
+	framework.ExpectNoError(wait.Poll(5*time.Second, time.Minute, func() (bool, error) {
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
+			Type:    certificatesv1beta1.CertificateApproved,
+			Reason:  "E2E",
+			Message: "Set from an e2e test",
+		})
+		var err error
+		csr, err = csrs.UpdateApproval(context.TODO(), csr, metav1.UpdateOptions{})
+		if err != nil {
+			csr, _ = csrs.Get(context.TODO(), csrName, metav1.GetOptions{})
+			framework.Logf("err updating approval: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}))
+	framework.Logf("waiting for CSR %q to be signed", csrName)
+	framework.ExpectNoError(wait.Poll(5*time.Second, time.Minute, func() (bool, error) {
+		csr, err := csrs.Get(context.TODO(), csrName, metav1.GetOptions{})
+		if err != nil {
+			framework.Logf("error getting csr: %v", err)
+			return false, nil
+		}
+		if len(csr.Status.Certificate) == 0 {
+			framework.Logf("csr not signed yet")
+			return false, nil
+		}
+		return true, nil
+	}))
+}
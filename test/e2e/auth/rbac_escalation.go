@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2eauth "k8s.io/kubernetes/test/e2e/framework/auth"
+	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+
+	"github.com/onsi/ginkgo"
+)
+
+var _ = SIGDescribe("RBAC escalation prevention", func() {
+
+	f := framework.NewDefaultFramework("rbac-escalation")
+	// client that will impersonate the limited user
+	var c clientset.Interface
+	var ns string
+	var asUser string
+
+	ginkgo.BeforeEach(func() {
+		if !e2eauth.IsRBACEnabled(f.ClientSet.RbacV1()) {
+			e2eskipper.Skipf("RBAC not enabled.")
+		}
+		ns = f.Namespace.Name
+		asUser = "e2e-rbac-escalation-" + ns
+
+		ginkgo.By("Granting the user permission to manage roles and rolebindings, but little else")
+		_, err := f.ClientSet.RbacV1().Roles(ns).Create(context.TODO(), &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "role-manager"},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{rbacv1.GroupName},
+					Resources: []string{"roles", "rolebindings"},
+					Verbs:     []string{"create"},
+				},
+				{
+					APIGroups: []string{""},
+					Resources: []string{"pods"},
+					Verbs:     []string{"get", "list"},
+				},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create role granting role management")
+		err = e2eauth.BindRoleInNamespace(f.ClientSet.RbacV1(), "role-manager", ns, rbacv1.Subject{
+			Kind:     rbacv1.UserKind,
+			APIGroup: rbacv1.GroupName,
+			Name:     asUser,
+		})
+		framework.ExpectNoError(err, "failed to bind role-manager role to user %q", asUser)
+		err = e2eauth.WaitForAuthorizationUpdate(f.ClientSet.AuthorizationV1(),
+			asUser, ns, "create", schema.GroupResource{Group: rbacv1.GroupName, Resource: "roles"}, true)
+		framework.ExpectNoError(err, "failed to wait for authorization update")
+
+		ginkgo.By("Creating a kubernetes client that impersonates the user")
+		config, err := framework.LoadConfig()
+		framework.ExpectNoError(err, "failed to load kubernetes client config")
+		config.Impersonate = restclient.ImpersonationConfig{
+			UserName: asUser,
+		}
+		c, err = clientset.NewForConfig(config)
+		framework.ExpectNoError(err, "failed to create Clientset for the given config: %+v", *config)
+	})
+
+	ginkgo.It("should reject creating a role that grants permissions the user does not hold", func() {
+		_, err := c.RbacV1().Roles(ns).Create(context.TODO(), secretReaderRole(), metav1.CreateOptions{})
+		framework.ExpectEqual(apierrors.IsForbidden(err), true, "expected escalating role create to be forbidden, got %v", err)
+	})
+
+	ginkgo.It("should reject binding a role that grants permissions the user does not hold", func() {
+		_, err := c.RbacV1().RoleBindings(ns).Create(context.TODO(), adminRoleBinding(asUser), metav1.CreateOptions{})
+		framework.ExpectEqual(apierrors.IsForbidden(err), true, "expected escalating rolebinding create to be forbidden, got %v", err)
+	})
+
+	ginkgo.It("should allow creating a broader role when the escalate verb is granted", func() {
+		ginkgo.By("Granting the user the escalate verb on roles")
+		_, err := f.ClientSet.RbacV1().Roles(ns).Create(context.TODO(), &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "role-escalator"},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{rbacv1.GroupName},
+					Resources: []string{"roles"},
+					Verbs:     []string{"escalate"},
+				},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create role granting the escalate verb")
+		err = e2eauth.BindRoleInNamespace(f.ClientSet.RbacV1(), "role-escalator", ns, rbacv1.Subject{
+			Kind:     rbacv1.UserKind,
+			APIGroup: rbacv1.GroupName,
+			Name:     asUser,
+		})
+		framework.ExpectNoError(err, "failed to bind role-escalator role to user %q", asUser)
+		err = e2eauth.WaitForAuthorizationUpdate(f.ClientSet.AuthorizationV1(),
+			asUser, ns, "escalate", schema.GroupResource{Group: rbacv1.GroupName, Resource: "roles"}, true)
+		framework.ExpectNoError(err, "failed to wait for authorization update")
+
+		ginkgo.By("Creating a role granting permissions the user does not hold")
+		_, err = c.RbacV1().Roles(ns).Create(context.TODO(), secretReaderRole(), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "expected role create to be allowed with the escalate verb")
+	})
+
+	ginkgo.It("should allow binding a broader role when the bind verb is granted", func() {
+		ginkgo.By("Granting the user the bind verb on the admin cluster role")
+		_, err := f.ClientSet.RbacV1().Roles(ns).Create(context.TODO(), &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "role-binder"},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups:     []string{rbacv1.GroupName},
+					Resources:     []string{"clusterroles"},
+					ResourceNames: []string{"admin"},
+					Verbs:         []string{"bind"},
+				},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create role granting the bind verb")
+		err = e2eauth.BindRoleInNamespace(f.ClientSet.RbacV1(), "role-binder", ns, rbacv1.Subject{
+			Kind:     rbacv1.UserKind,
+			APIGroup: rbacv1.GroupName,
+			Name:     asUser,
+		})
+		framework.ExpectNoError(err, "failed to bind role-binder role to user %q", asUser)
+		err = e2eauth.WaitForNamedAuthorizationUpdate(f.ClientSet.AuthorizationV1(),
+			asUser, ns, "bind", "admin", schema.GroupResource{Group: rbacv1.GroupName, Resource: "clusterroles"}, true)
+		framework.ExpectNoError(err, "failed to wait for authorization update")
+
+		ginkgo.By("Binding the admin cluster role in the namespace")
+		_, err = c.RbacV1().RoleBindings(ns).Create(context.TODO(), adminRoleBinding(asUser), metav1.CreateOptions{})
+		framework.ExpectNoError(err, "expected rolebinding create to be allowed with the bind verb")
+	})
+})
+
+// secretReaderRole returns a role granting permissions on secrets, which the
+// impersonated user does not hold.
+func secretReaderRole() *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret-reader"},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get", "list"},
+			},
+		},
+	}
+}
+
+// adminRoleBinding returns a rolebinding granting the user the admin cluster
+// role, which holds more permissions than the user does.
+func adminRoleBinding(user string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "escalating-binding"},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "admin",
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:     rbacv1.UserKind,
+				APIGroup: rbacv1.GroupName,
+				Name:     user,
+			},
+		},
+	}
+}
@@ -253,6 +253,81 @@ var _ = SIGDescribe("ServiceAccounts", func() {
 		framework.ExpectEqual(groups.Has("system:serviceaccounts:"+f.Namespace.Name), true, fmt.Sprintf("expected system:serviceaccounts:"+f.Namespace.Name+" group, had %v", groups.List()))
 	})
 
+	ginkgo.It("should invalidate a pod-bound token when the pod is deleted [Feature:TokenRequest]", func() {
+		zero := int64(0)
+		pod, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod-token-bound-" + string(uuid.NewUUID()),
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Name:    "test",
+					Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+					Command: []string{"sleep", "100000"},
+				}},
+				TerminationGracePeriodSeconds: &zero,
+				RestartPolicy:                 v1.RestartPolicyNever,
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(e2epod.WaitForPodRunningInNamespace(f.ClientSet, pod))
+
+		ginkgo.By("requesting a token bound to the pod")
+		tokenRequest, err := f.ClientSet.CoreV1().ServiceAccounts(f.Namespace.Name).CreateToken(context.TODO(), "default", &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				BoundObjectRef: &authenticationv1.BoundObjectReference{
+					Kind:       "Pod",
+					APIVersion: "v1",
+					Name:       pod.Name,
+					UID:        pod.UID,
+				},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("verifying the token is valid while the pod exists")
+		tokenReview := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: tokenRequest.Status.Token}}
+		tokenReview, err = f.ClientSet.AuthenticationV1().TokenReviews().Create(context.TODO(), tokenReview, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(tokenReview.Status.Authenticated, true)
+		framework.ExpectEqual(tokenReview.Status.User.Username, "system:serviceaccount:"+f.Namespace.Name+":default")
+
+		ginkgo.By("deleting the pod the token is bound to")
+		err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Delete(context.TODO(), pod.Name, *metav1.NewDeleteOptions(0))
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(e2epod.WaitForPodNotFoundInNamespace(f.ClientSet, pod.Name, f.Namespace.Name, time.Minute))
+
+		ginkgo.By("verifying the token is rejected once the pod is gone")
+		// The token authenticator may serve the pod lookup from a cache, so
+		// poll until the deletion is observed.
+		framework.ExpectNoError(wait.Poll(framework.Poll, 2*time.Minute, func() (bool, error) {
+			tokenReview := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: tokenRequest.Status.Token}}
+			tokenReview, err := f.ClientSet.AuthenticationV1().TokenReviews().Create(context.TODO(), tokenReview, metav1.CreateOptions{})
+			if err != nil {
+				return false, err
+			}
+			if tokenReview.Status.Authenticated {
+				framework.Logf("token for deleted pod %q is still accepted", pod.Name)
+				return false, nil
+			}
+			return true, nil
+		}), "token bound to deleted pod was not invalidated")
+
+		ginkgo.By("verifying tokens cannot be bound to Node objects on this version")
+		// Binding tokens to Node objects is not supported by this API server
+		// version; the request must be rejected rather than silently unbound.
+		_, err = f.ClientSet.CoreV1().ServiceAccounts(f.Namespace.Name).CreateToken(context.TODO(), "default", &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				BoundObjectRef: &authenticationv1.BoundObjectReference{
+					Kind:       "Node",
+					APIVersion: "v1",
+					Name:       "some-node",
+				},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectEqual(apierrors.IsBadRequest(err), true, "expected node-bound token request to be rejected, got %v", err)
+	})
+
 	/*
 	   Release: v1.9
 	   Testname: Service account tokens auto mount optionally
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"time"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2eautoscaling "k8s.io/kubernetes/test/e2e/framework/autoscaling"
+	utilpointer "k8s.io/utils/pointer"
+
+	"github.com/onsi/ginkgo"
+)
+
+// maxHPAReactionTime is the slack allowed for the HPA controller and the
+// metrics pipeline to observe a load change; timing assertions below must
+// stay tolerant of it.
+const maxHPAReactionTime = 90 * time.Second
+
+var _ = SIGDescribe("[Feature:HPA] [Serial] [Slow] Horizontal pod autoscaling (scale behavior)", func() {
+	f := framework.NewDefaultFramework("horizontal-pod-autoscaling-behavior")
+
+	ginkgo.It("should scale up only by the number of pods the scale-up policy allows per period", func() {
+		initPods := 1
+		scaleUpPeriodSeconds := int32(60)
+		// 250 mCPU against a 20% target on 500m requests asks for 3 replicas,
+		// but the policy only allows adding 1 pod per period.
+		rc := e2eautoscaling.NewDynamicResourceConsumer("consumer", f.Namespace.Name, e2eautoscaling.KindDeployment, initPods, 250, 0, 0, 500, 200, f.ClientSet, f.ScalesGetter)
+		defer rc.CleanUp()
+
+		behavior := &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleUp: &autoscalingv2beta2.HPAScalingRules{
+				StabilizationWindowSeconds: utilpointer.Int32Ptr(0),
+				Policies: []autoscalingv2beta2.HPAScalingPolicy{
+					{
+						Type:          autoscalingv2beta2.PodsScalingPolicy,
+						Value:         1,
+						PeriodSeconds: scaleUpPeriodSeconds,
+					},
+				},
+			},
+		}
+		hpa := e2eautoscaling.CreateCPUHorizontalPodAutoscalerWithBehavior(rc, 20, 1, 3, behavior)
+		defer e2eautoscaling.DeleteHorizontalPodAutoscalerWithBehavior(rc, hpa.Name)
+
+		scaleUpPeriod := time.Duration(scaleUpPeriodSeconds) * time.Second
+
+		ginkgo.By("waiting for the first limited scale-up step")
+		rc.WaitForReplicas(2, maxHPAReactionTime+scaleUpPeriod)
+
+		ginkgo.By("waiting for the second limited scale-up step")
+		waitStart := time.Now()
+		rc.WaitForReplicas(3, maxHPAReactionTime+2*scaleUpPeriod)
+		timeWaited := time.Since(waitStart)
+
+		// The second step has to wait for a new policy period. Only assert a
+		// lower bound of half a period so that controller sync jitter cannot
+		// turn this into a flake.
+		framework.Logf("time waited between replica counts 2 and 3: %v", timeWaited)
+		if timeWaited < scaleUpPeriod/2 {
+			framework.Failf("HPA scaled from 2 to 3 replicas after %v, violating the 1 pod per %v scale-up policy", timeWaited, scaleUpPeriod)
+		}
+	})
+
+	ginkgo.It("should not scale down before the scale-down stabilization window has passed", func() {
+		initPods := 2
+		stabilizationSeconds := int32(180)
+		// 300 mCPU against a 30% target on 500m requests keeps 2 replicas busy.
+		rc := e2eautoscaling.NewDynamicResourceConsumer("consumer", f.Namespace.Name, e2eautoscaling.KindDeployment, initPods, 300, 0, 0, 500, 200, f.ClientSet, f.ScalesGetter)
+		defer rc.CleanUp()
+
+		behavior := &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleDown: &autoscalingv2beta2.HPAScalingRules{
+				StabilizationWindowSeconds: utilpointer.Int32Ptr(stabilizationSeconds),
+				Policies: []autoscalingv2beta2.HPAScalingPolicy{
+					{
+						Type:          autoscalingv2beta2.PercentScalingPolicy,
+						Value:         100,
+						PeriodSeconds: 15,
+					},
+				},
+			},
+		}
+		hpa := e2eautoscaling.CreateCPUHorizontalPodAutoscalerWithBehavior(rc, 30, 1, 3, behavior)
+		defer e2eautoscaling.DeleteHorizontalPodAutoscalerWithBehavior(rc, hpa.Name)
+
+		rc.WaitForReplicas(initPods, maxHPAReactionTime)
+
+		ginkgo.By("dropping the load so that only one replica is needed")
+		rc.ConsumeCPU(50)
+
+		// The stabilization window starts once the lower recommendation is
+		// recorded, which is at least one reaction time after the load drop,
+		// so the replica count has to hold for the full window from here.
+		stabilization := time.Duration(stabilizationSeconds) * time.Second
+		ginkgo.By("verifying the replica count stays up during the stabilization window")
+		rc.EnsureDesiredReplicasInRange(initPods, initPods, stabilization, hpa.Name)
+
+		ginkgo.By("waiting for the scale down once the stabilization window has passed")
+		rc.WaitForReplicas(1, maxHPAReactionTime+stabilization)
+	})
+})
@@ -60,6 +60,9 @@ var upgradeTests = []upgrades.Test{
 	&apps.DeploymentUpgradeTest{},
 	&apps.JobUpgradeTest{},
 	&upgrades.ConfigMapUpgradeTest{},
+	&upgrades.LimitRangeUpgradeTest{},
+	&apps.ControllerRevisionUpgradeTest{},
+	&storage.VolumeAttachmentUpgradeTest{},
 	&upgrades.HPAUpgradeTest{},
 	&storage.PersistentVolumeUpgradeTest{},
 	&apps.DaemonSetUpgradeTest{},
@@ -186,7 +189,7 @@ var _ = SIGDescribe("Downgrade [Feature:Downgrade]", func() {
 				framework.ExpectNoError(framework.MasterUpgrade(f, target))
 				framework.ExpectNoError(checkMasterVersion(f.ClientSet, target))
 			}
-			runUpgradeSuite(f, upgradeTests, testFrameworks, testSuite, upgrades.ClusterUpgrade, upgradeFunc)
+			runDowngradeSuite(f, upgradeTests, testFrameworks, testSuite, upgrades.ClusterUpgrade, upgradeFunc)
 		})
 	})
 })
@@ -274,7 +277,7 @@ var _ = SIGDescribe("gpu Upgrade [Feature:GPUUpgrade]", func() {
 				framework.ExpectNoError(framework.MasterUpgrade(f, target))
 				framework.ExpectNoError(checkMasterVersion(f.ClientSet, target))
 			}
-			runUpgradeSuite(f, gpuUpgradeTests, testFrameworks, testSuite, upgrades.ClusterUpgrade, upgradeFunc)
+			runDowngradeSuite(f, gpuUpgradeTests, testFrameworks, testSuite, upgrades.ClusterUpgrade, upgradeFunc)
 		})
 	})
 })
@@ -365,7 +368,7 @@ var _ = SIGDescribe("kube-proxy migration [Feature:KubeProxyDaemonSetMigration]"
 				framework.ExpectNoError(framework.MasterUpgradeGCEWithKubeProxyDaemonSet(target, false))
 				framework.ExpectNoError(checkMasterVersion(f.ClientSet, target))
 			}
-			runUpgradeSuite(f, kubeProxyDowngradeTests, testFrameworks, testSuite, upgrades.ClusterUpgrade, upgradeFunc)
+			runDowngradeSuite(f, kubeProxyDowngradeTests, testFrameworks, testSuite, upgrades.ClusterUpgrade, upgradeFunc)
 		})
 	})
 })
@@ -376,6 +379,7 @@ type chaosMonkeyAdapter struct {
 	framework   *framework.Framework
 	upgradeType upgrades.UpgradeType
 	upgCtx      upgrades.UpgradeContext
+	isDowngrade bool
 }
 
 func (cma *chaosMonkeyAdapter) Test(sem *chaosmonkey.Semaphore) {
@@ -396,8 +400,15 @@ func (cma *chaosMonkeyAdapter) Test(sem *chaosmonkey.Semaphore) {
 
 	defer cma.test.Teardown(cma.framework)
 	cma.test.Setup(cma.framework)
+	downgradeTest, hasDowngradeHooks := cma.test.(upgrades.DowngradeTest)
+	if cma.isDowngrade && hasDowngradeHooks {
+		downgradeTest.PreDowngrade(cma.framework)
+	}
 	ready()
 	cma.test.Test(cma.framework, sem.StopCh, cma.upgradeType)
+	if cma.isDowngrade && hasDowngradeHooks {
+		downgradeTest.PostDowngrade(cma.framework)
+	}
 }
 
 func finalizeUpgradeTest(start time.Time, tc *junit.TestCase) {
@@ -447,6 +458,32 @@ func runUpgradeSuite(
 	testSuite *junit.TestSuite,
 	upgradeType upgrades.UpgradeType,
 	upgradeFunc func(),
+) {
+	runSuite(f, tests, testFrameworks, testSuite, upgradeType, upgradeFunc, false)
+}
+
+// runDowngradeSuite is runUpgradeSuite for suites whose upgradeFunc rolls the
+// cluster back to an older version; it additionally runs the PreDowngrade and
+// PostDowngrade hooks of tests that implement upgrades.DowngradeTest.
+func runDowngradeSuite(
+	f *framework.Framework,
+	tests []upgrades.Test,
+	testFrameworks map[string]*framework.Framework,
+	testSuite *junit.TestSuite,
+	upgradeType upgrades.UpgradeType,
+	upgradeFunc func(),
+) {
+	runSuite(f, tests, testFrameworks, testSuite, upgradeType, upgradeFunc, true)
+}
+
+func runSuite(
+	f *framework.Framework,
+	tests []upgrades.Test,
+	testFrameworks map[string]*framework.Framework,
+	testSuite *junit.TestSuite,
+	upgradeType upgrades.UpgradeType,
+	upgradeFunc func(),
+	isDowngrade bool,
 ) {
 	upgCtx, err := getUpgradeContext(f.ClientSet.Discovery(), *upgradeTarget)
 	framework.ExpectNoError(err)
@@ -464,6 +501,7 @@ func runUpgradeSuite(
 			framework:   testFrameworks[t.Name()],
 			upgradeType: upgradeType,
 			upgCtx:      *upgCtx,
+			isDowngrade: isDowngrade,
 		}
 		cm.Register(cma.Test)
 	}
@@ -0,0 +1,292 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2ewebsocket "k8s.io/kubernetes/test/e2e/framework/websocket"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+	uexec "k8s.io/utils/exec"
+
+	"github.com/onsi/ginkgo"
+	"golang.org/x/net/websocket"
+)
+
+// Channel numbers of the websocket channel protocols used by the exec and
+// attach subresources.
+const (
+	wsStdinChannel  = 0
+	wsStdoutChannel = 1
+	wsStderrChannel = 2
+	wsErrorChannel  = 3
+	wsResizeChannel = 4
+)
+
+var _ = framework.KubeDescribe("Pods Exec Streams", func() {
+	f := framework.NewDefaultFramework("pods-exec-streams")
+	var podClient *framework.PodClient
+
+	ginkgo.BeforeEach(func() {
+		podClient = f.PodClient()
+	})
+
+	// execRequest builds a pods/exec request for the given pod and options.
+	execRequest := func(podName string, opts *v1.PodExecOptions) *url.URL {
+		req := f.ClientSet.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Name(podName).
+			Namespace(f.Namespace.Name).
+			SubResource("exec")
+		req.VersionedParams(opts, scheme.ParameterCodec)
+		return req.URL()
+	}
+
+	createSleepingPod := func(name string) *v1.Pod {
+		return podClient.CreateSync(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:    "main",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"/bin/sh", "-c", "sleep 600"},
+					},
+				},
+			},
+		})
+	}
+
+	ginkgo.It("should plumb stdin, stdout, stderr and exit codes over the SPDY executor [NodeConformance]", func() {
+		config, err := framework.LoadConfig()
+		framework.ExpectNoError(err, "unable to get base config")
+
+		ginkgo.By("creating the pod")
+		pod := createSleepingPod("exec-spdy-" + f.UniqueName)
+
+		ginkgo.By("executing a command that reads stdin and writes to stdout and stderr")
+		opts := &v1.PodExecOptions{
+			Container: "main",
+			Command:   []string{"/bin/sh", "-c", "cat; echo out-marker; echo err-marker >&2"},
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}
+		exec, err := remotecommand.NewSPDYExecutor(config, "POST", execRequest(pod.Name, opts))
+		framework.ExpectNoError(err)
+		var stdout, stderr bytes.Buffer
+		err = exec.Stream(remotecommand.StreamOptions{
+			Stdin:  strings.NewReader("stdin-marker\n"),
+			Stdout: &stdout,
+			Stderr: &stderr,
+		})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(strings.Contains(stdout.String(), "stdin-marker"), true, "stdin was not echoed back on stdout: %q", stdout.String())
+		framework.ExpectEqual(strings.Contains(stdout.String(), "out-marker"), true, "missing stdout output: %q", stdout.String())
+		framework.ExpectEqual(strings.Contains(stderr.String(), "err-marker"), true, "missing stderr output: %q", stderr.String())
+
+		ginkgo.By("verifying a non-zero exit code is propagated as a CodeExitError")
+		opts = &v1.PodExecOptions{
+			Container: "main",
+			Command:   []string{"/bin/sh", "-c", "exit 42"},
+			Stdout:    true,
+			Stderr:    true,
+		}
+		exec, err = remotecommand.NewSPDYExecutor(config, "POST", execRequest(pod.Name, opts))
+		framework.ExpectNoError(err)
+		err = exec.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+		exitErr, ok := err.(uexec.CodeExitError)
+		framework.ExpectEqual(ok, true, "expected a CodeExitError, got %v", err)
+		framework.ExpectEqual(exitErr.ExitStatus(), 42)
+	})
+
+	ginkgo.It("should plumb stdin, stdout, stderr and exit codes over the WebSocket channel protocol [NodeConformance]", func() {
+		config, err := framework.LoadConfig()
+		framework.ExpectNoError(err, "unable to get base config")
+
+		ginkgo.By("creating the pod")
+		pod := createSleepingPod("exec-websocket-" + f.UniqueName)
+
+		ginkgo.By("executing a command that reads a line from stdin and exits non-zero")
+		opts := &v1.PodExecOptions{
+			Container: "main",
+			Command:   []string{"/bin/sh", "-c", "read line; echo $line; echo err-marker >&2; exit 7"},
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}
+		ws, err := e2ewebsocket.OpenWebSocketForURL(execRequest(pod.Name, opts), config, []string{"v4.channel.k8s.io"})
+		framework.ExpectNoError(err)
+		defer ws.Close()
+
+		ginkgo.By("sending a line on the stdin channel")
+		err = websocket.Message.Send(ws, append([]byte{wsStdinChannel}, []byte("websocket-stdin\n")...))
+		framework.ExpectNoError(err)
+
+		ginkgo.By("collecting the demultiplexed channel output")
+		stdout, stderr, status := readWebSocketChannels(ws)
+		framework.ExpectEqual(strings.Contains(stdout, "websocket-stdin"), true, "stdin was not echoed back on stdout: %q", stdout)
+		framework.ExpectEqual(strings.Contains(stderr, "err-marker"), true, "missing stderr output: %q", stderr)
+
+		ginkgo.By("verifying the exit code arrived on the error channel")
+		if status == nil {
+			framework.Failf("no status was received on the error channel")
+		}
+		framework.ExpectEqual(status.Status, metav1.StatusFailure)
+		framework.ExpectEqual(exitCodeFromStatus(status), "7")
+	})
+
+	ginkgo.It("should apply TTY resize messages sent on the websocket resize channel [NodeConformance]", func() {
+		config, err := framework.LoadConfig()
+		framework.ExpectNoError(err, "unable to get base config")
+
+		ginkgo.By("creating the pod")
+		pod := createSleepingPod("exec-resize-" + f.UniqueName)
+
+		ginkgo.By("executing stty inside a TTY after sending a resize message")
+		opts := &v1.PodExecOptions{
+			Container: "main",
+			// Give the resize message a moment to be applied before reading
+			// the terminal size.
+			Command: []string{"/bin/sh", "-c", "sleep 2; stty size"},
+			Stdin:   true,
+			Stdout:  true,
+			TTY:     true,
+		}
+		ws, err := e2ewebsocket.OpenWebSocketForURL(execRequest(pod.Name, opts), config, []string{"v4.channel.k8s.io"})
+		framework.ExpectNoError(err)
+		defer ws.Close()
+
+		resize, err := json.Marshal(remotecommand.TerminalSize{Width: 123, Height: 45})
+		framework.ExpectNoError(err)
+		err = websocket.Message.Send(ws, append([]byte{wsResizeChannel}, resize...))
+		framework.ExpectNoError(err)
+
+		stdout, _, _ := readWebSocketChannels(ws)
+		framework.ExpectEqual(strings.Contains(stdout, "45 123"), true, "stty did not report the resized terminal: %q", stdout)
+	})
+
+	ginkgo.It("should support attach over both SPDY and WebSocket [NodeConformance]", func() {
+		config, err := framework.LoadConfig()
+		framework.ExpectNoError(err, "unable to get base config")
+
+		attachURL := func(podName string) *url.URL {
+			req := f.ClientSet.CoreV1().RESTClient().Post().
+				Resource("pods").
+				Name(podName).
+				Namespace(f.Namespace.Name).
+				SubResource("attach")
+			req.VersionedParams(&v1.PodAttachOptions{
+				Container: "main",
+				Stdout:    true,
+				Stderr:    true,
+			}, scheme.ParameterCodec)
+			return req.URL()
+		}
+		// The container stays quiet long enough for the attach to be
+		// established, emits its marker and exits, ending the stream.
+		createAttachPod := func(name string) *v1.Pod {
+			return podClient.CreateSync(&v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyNever,
+					Containers: []v1.Container{
+						{
+							Name:    "main",
+							Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+							Command: []string{"/bin/sh", "-c", "sleep 10; echo attach-marker; sleep 1"},
+						},
+					},
+				},
+			})
+		}
+
+		ginkgo.By("attaching over SPDY")
+		pod := createAttachPod("attach-spdy-" + f.UniqueName)
+		attach, err := remotecommand.NewSPDYExecutor(config, "POST", attachURL(pod.Name))
+		framework.ExpectNoError(err)
+		var stdout, stderr bytes.Buffer
+		err = attach.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(strings.Contains(stdout.String(), "attach-marker"), true, "attach did not stream the container output: %q", stdout.String())
+
+		ginkgo.By("attaching over WebSocket")
+		pod = createAttachPod("attach-websocket-" + f.UniqueName)
+		ws, err := e2ewebsocket.OpenWebSocketForURL(attachURL(pod.Name), config, []string{"v4.channel.k8s.io"})
+		framework.ExpectNoError(err)
+		defer ws.Close()
+		wsStdout, _, _ := readWebSocketChannels(ws)
+		framework.ExpectEqual(strings.Contains(wsStdout, "attach-marker"), true, "attach did not stream the container output: %q", wsStdout)
+	})
+})
+
+// readWebSocketChannels reads messages from a v4 channel protocol websocket
+// until EOF and demultiplexes them into stdout, stderr and the status object
+// sent on the error channel, if any.
+func readWebSocketChannels(ws *websocket.Conn) (string, string, *metav1.Status) {
+	var stdout, stderr bytes.Buffer
+	var status *metav1.Status
+	ws.SetReadDeadline(time.Now().Add(2 * time.Minute))
+	for {
+		var msg []byte
+		if err := websocket.Message.Receive(ws, &msg); err != nil {
+			if err != io.EOF {
+				framework.Failf("Failed to read completely from websocket: %v", err)
+			}
+			break
+		}
+		if len(msg) < 2 {
+			continue
+		}
+		switch msg[0] {
+		case wsStdoutChannel:
+			stdout.Write(msg[1:])
+		case wsStderrChannel:
+			stderr.Write(msg[1:])
+		case wsErrorChannel:
+			status = &metav1.Status{}
+			if err := json.Unmarshal(msg[1:], status); err != nil {
+				framework.Failf("Failed to decode status from error channel: %v", err)
+			}
+		}
+	}
+	return stdout.String(), stderr.String(), status
+}
+
+// exitCodeFromStatus extracts the exit code detail from a status returned on
+// the exec error channel.
+func exitCodeFromStatus(status *metav1.Status) string {
+	if status.Details == nil {
+		return ""
+	}
+	for _, cause := range status.Details.Causes {
+		if cause.Type == "ExitCode" {
+			return cause.Message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+
+	"github.com/onsi/ginkgo"
+)
+
+var _ = framework.KubeDescribe("Pods Log", func() {
+	f := framework.NewDefaultFramework("pods-log")
+	var podClient *framework.PodClient
+
+	ginkgo.BeforeEach(func() {
+		podClient = f.PodClient()
+	})
+
+	// readLogs fetches logs through the pods/log subresource with the given
+	// options and returns them as a string.
+	readLogs := func(podName string, opts *v1.PodLogOptions) (string, error) {
+		stream, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).GetLogs(podName, opts).Stream(context.TODO())
+		if err != nil {
+			return "", err
+		}
+		defer stream.Close()
+		logs, err := ioutil.ReadAll(stream)
+		return string(logs), err
+	}
+
+	ginkgo.It("should support the pods/log subresource option matrix [NodeConformance]", func() {
+		podName := "logs-options-" + f.UniqueName
+		const lineCount = 10
+		// Each container writes an identifiable set of lines once at startup,
+		// then sleeps so the log stream stays quiet for the since* checks.
+		logScript := func(prefix string) string {
+			return fmt.Sprintf("i=1; while [ $i -le %d ]; do echo %s-line-$i; i=$((i+1)); done; sleep 10000", lineCount, prefix)
+		}
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podName},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:    "logger-1",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"/bin/sh", "-c", logScript("first")},
+					},
+					{
+						Name:    "logger-2",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"/bin/sh", "-c", logScript("second")},
+					},
+				},
+			},
+		}
+
+		ginkgo.By("creating a pod with two containers writing known log lines")
+		podClient.CreateSync(pod)
+
+		waitForLines := func(container, prefix string) {
+			lastLine := fmt.Sprintf("%s-line-%d", prefix, lineCount)
+			framework.ExpectNoError(wait.PollImmediate(framework.Poll, 2*time.Minute, func() (bool, error) {
+				logs, err := readLogs(podName, &v1.PodLogOptions{Container: container})
+				if err != nil {
+					return false, nil
+				}
+				return strings.Contains(logs, lastLine), nil
+			}), "container %s never logged %q", container, lastLine)
+		}
+		waitForLines("logger-1", "first")
+		waitForLines("logger-2", "second")
+
+		ginkgo.By("verifying container selection returns only that container's logs")
+		logs, err := readLogs(podName, &v1.PodLogOptions{Container: "logger-2"})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(strings.Contains(logs, "second-line-1"), true)
+		framework.ExpectEqual(strings.Contains(logs, "first-line-1"), false)
+
+		ginkgo.By("verifying tailLines limits the number of returned lines")
+		tail := int64(3)
+		logs, err = readLogs(podName, &v1.PodLogOptions{Container: "logger-1", TailLines: &tail})
+		framework.ExpectNoError(err)
+		lines := strings.Split(strings.TrimSpace(logs), "\n")
+		framework.ExpectEqual(len(lines), 3)
+		framework.ExpectEqual(lines[2], fmt.Sprintf("first-line-%d", lineCount))
+
+		ginkgo.By("verifying limitBytes truncates the response")
+		limit := int64(5)
+		logs, err = readLogs(podName, &v1.PodLogOptions{Container: "logger-1", LimitBytes: &limit})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(logs), 5)
+
+		ginkgo.By("verifying timestamps prefixes every line with a parseable time")
+		logs, err = readLogs(podName, &v1.PodLogOptions{Container: "logger-1", Timestamps: true})
+		framework.ExpectNoError(err)
+		for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+			parts := strings.SplitN(line, " ", 2)
+			framework.ExpectEqual(len(parts), 2, "log line %q has no timestamp prefix", line)
+			_, err := time.Parse(time.RFC3339Nano, parts[0])
+			framework.ExpectNoError(err, "log line %q has an unparseable timestamp", line)
+		}
+
+		ginkgo.By("verifying sinceSeconds with a large window returns the full log")
+		since := int64(3600)
+		logs, err = readLogs(podName, &v1.PodLogOptions{Container: "logger-1", SinceSeconds: &since})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(strings.Contains(logs, "first-line-1"), true)
+
+		ginkgo.By("verifying sinceTime after the last write returns no lines")
+		sinceTime := metav1.Now()
+		logs, err = readLogs(podName, &v1.PodLogOptions{Container: "logger-1", SinceTime: &sinceTime})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(strings.TrimSpace(logs), "")
+	})
+
+	ginkgo.It("should support follow and previous on the pods/log subresource [NodeConformance]", func() {
+		podName := "logs-stream-" + f.UniqueName
+		// The container writes its lines and exits non-zero, so a follow read
+		// terminates at EOF and a restarted instance leaves previous logs.
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podName},
+			Spec: v1.PodSpec{
+				RestartPolicy: v1.RestartPolicyAlways,
+				Containers: []v1.Container{
+					{
+						Name:    "logger",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"/bin/sh", "-c", "echo stream-line-1; echo stream-line-2; sleep 5; exit 1"},
+					},
+				},
+			},
+		}
+
+		ginkgo.By("creating a pod whose container writes logs and exits")
+		podClient.Create(pod)
+		framework.ExpectNoError(e2epod.WaitForPodNameRunningInNamespace(f.ClientSet, podName, f.Namespace.Name))
+
+		ginkgo.By("streaming logs with follow until the container terminates")
+		stream, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).GetLogs(podName, &v1.PodLogOptions{Container: "logger", Follow: true}).Stream(context.TODO())
+		framework.ExpectNoError(err)
+		defer stream.Close()
+		followed, err := ioutil.ReadAll(stream)
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(strings.Contains(string(followed), "stream-line-2"), true)
+
+		ginkgo.By("waiting for the container to restart")
+		framework.ExpectNoError(wait.PollImmediate(framework.Poll, 2*time.Minute, func() (bool, error) {
+			pod, err := podClient.Get(context.TODO(), podName, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			status := pod.Status.ContainerStatuses
+			return len(status) > 0 && status[0].RestartCount > 0 && status[0].State.Running != nil, nil
+		}), "container never restarted")
+
+		ginkgo.By("reading the previous instance's logs")
+		stream, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).GetLogs(podName, &v1.PodLogOptions{Container: "logger", Previous: true}).Stream(context.TODO())
+		framework.ExpectNoError(err)
+		defer stream.Close()
+		previous, err := ioutil.ReadAll(stream)
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(strings.Contains(string(previous), "stream-line-1"), true)
+	})
+})
@@ -36,9 +36,11 @@ import (
 	"github.com/onsi/ginkgo/reporters"
 	"github.com/onsi/gomega"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtimeutils "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/component-base/logs"
 	"k8s.io/component-base/version"
@@ -48,8 +50,10 @@ import (
 	e2emanifest "k8s.io/kubernetes/test/e2e/framework/manifest"
 	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2essh "k8s.io/kubernetes/test/e2e/framework/ssh"
 	e2ereporters "k8s.io/kubernetes/test/e2e/reporters"
 	testutils "k8s.io/kubernetes/test/utils"
+	imageutils "k8s.io/kubernetes/test/utils/image"
 	utilnet "k8s.io/utils/net"
 
 	clientset "k8s.io/client-go/kubernetes"
@@ -59,6 +63,7 @@ import (
 	// ensure that cloud providers are loaded
 	_ "k8s.io/kubernetes/test/e2e/framework/providers/aws"
 	_ "k8s.io/kubernetes/test/e2e/framework/providers/azure"
+	_ "k8s.io/kubernetes/test/e2e/framework/providers/external"
 	_ "k8s.io/kubernetes/test/e2e/framework/providers/gce"
 	_ "k8s.io/kubernetes/test/e2e/framework/providers/kubemark"
 	_ "k8s.io/kubernetes/test/e2e/framework/providers/openstack"
@@ -71,6 +76,10 @@ const (
 	// on a long lived cluster. A long wait here is preferably to spurious test
 	// failures caused by leaked resources from a previous test run.
 	namespaceCleanupTimeout = 15 * time.Minute
+
+	// imagePrepullTimeout is how long to wait for the image puller DaemonSets
+	// to pull every test image onto every node when --prepull-images is set.
+	imagePrepullTimeout = 10 * time.Minute
 )
 
 var _ = ginkgo.SynchronizedBeforeSuite(func() []byte {
@@ -100,11 +109,20 @@ func RunE2ETests(t *testing.T) {
 	defer logs.FlushLogs()
 
 	gomega.RegisterFailHandler(framework.Fail)
+	// Clean up registered actions and this run's namespaces if the run is aborted.
+	startInterruptHandler()
 	// Disable skipped tests unless they are explicitly requested.
 	if config.GinkgoConfig.FocusString == "" && config.GinkgoConfig.SkipString == "" {
 		config.GinkgoConfig.SkipString = `\[Flaky\]|\[Feature:.+\]`
 	}
 
+	// Retry failed specs when the suite was started with --flake-attempts
+	// greater than one. Ginkgo reports specs that eventually pass as flaked
+	// without failing the suite.
+	if framework.TestContext.FlakeAttempts > 1 {
+		config.GinkgoConfig.FlakeAttempts = framework.TestContext.FlakeAttempts
+	}
+
 	// Run tests through the Ginkgo runner with output to console + JUnit for Jenkins
 	var r []ginkgo.Reporter
 	if framework.TestContext.ReportDir != "" {
@@ -114,12 +132,23 @@ func RunE2ETests(t *testing.T) {
 			klog.Errorf("Failed creating report directory: %v", err)
 		} else {
 			r = append(r, reporters.NewJUnitReporter(path.Join(framework.TestContext.ReportDir, fmt.Sprintf("junit_%v%02d.xml", framework.TestContext.ReportPrefix, config.GinkgoConfig.ParallelNode))))
+			r = append(r, e2ereporters.NewJSONResultsReporterFile(path.Join(framework.TestContext.ReportDir, fmt.Sprintf("results_%v%02d.json", framework.TestContext.ReportPrefix, config.GinkgoConfig.ParallelNode))))
+			r = append(r, e2ereporters.NewJUnitStepsReporter(path.Join(framework.TestContext.ReportDir, fmt.Sprintf("junit_steps_%v%02d.xml", framework.TestContext.ReportPrefix, config.GinkgoConfig.ParallelNode))))
+			if framework.TestContext.FlakeAttempts > 1 || framework.TestContext.QuarantineListFile != "" {
+				r = append(r, e2ereporters.NewFlakeReporter(path.Join(framework.TestContext.ReportDir, fmt.Sprintf("flakes_%v%02d.json", framework.TestContext.ReportPrefix, config.GinkgoConfig.ParallelNode))))
+			}
 		}
 	}
 
 	// Stream the progress to stdout and optionally a URL accepting progress updates.
 	r = append(r, e2ereporters.NewProgressReporter(framework.TestContext.ProgressReportURL))
 
+	// Periodically report the currently executing spec and By step so hung
+	// steps can be detected before the suite timeout fires.
+	if framework.TestContext.HeartbeatInterval > 0 {
+		r = append(r, e2ereporters.NewHeartbeatReporter(framework.TestContext.HeartbeatInterval, framework.TestContext.ProgressReportURL))
+	}
+
 	// The DetailsRepoerter will output details about every test (name, files, lines, etc) which helps
 	// when documenting our tests.
 	if len(framework.TestContext.SpecSummaryOutput) > 0 {
@@ -215,6 +244,129 @@ func waitForDaemonSets(c clientset.Interface, ns string, allowedNotReadyNodes in
 	})
 }
 
+// verifyImageManifests resolves every test image tag to a digest and checks
+// that images published as manifest lists cover the architectures of the
+// cluster's nodes, failing the suite with a report of the unavailable images
+// before any spec can hit ErrImagePull.
+func verifyImageManifests(c clientset.Interface) {
+	nodes, err := e2enode.GetReadySchedulableNodes(c)
+	framework.ExpectNoError(err)
+	architectures := sets.NewString()
+	for _, node := range nodes.Items {
+		architectures.Insert(node.Status.NodeInfo.Architecture)
+	}
+
+	framework.Logf("Verifying test image manifests for node architectures %v", architectures.List())
+	if err := imageutils.VerifyImages(architectures.List()); err != nil {
+		framework.Failf("Test image verification failed: %v", err)
+	}
+}
+
+// prepullImages pulls all test images onto every schedulable node by running
+// one DaemonSet per image and waiting until the images show up in the puller
+// pods' container statuses. The pullers run the images as-is, so many of them
+// exit or crash right after starting; all that matters here is that the image
+// reached the node, not that the container stays up.
+func prepullImages(c clientset.Interface) {
+	images := imageutils.GetE2EImages()
+	framework.Logf("Pre-pulling %d test images", len(images))
+
+	ns, err := framework.CreateTestingNS("img-puller", c, nil)
+	framework.ExpectNoError(err)
+	defer func() {
+		err := c.CoreV1().Namespaces().Delete(context.TODO(), ns.Name, metav1.DeleteOptions{})
+		framework.ExpectNoError(err, "failed to delete image puller namespace %s", ns.Name)
+	}()
+
+	for i, image := range images {
+		ds := newImagePullDaemonSet(fmt.Sprintf("img-pull-%d", i), image)
+		_, err := c.AppsV1().DaemonSets(ns.Name).Create(context.TODO(), ds, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create image puller DaemonSet for %s", image)
+	}
+
+	framework.ExpectNoError(waitForImagesPulled(c, ns.Name, imagePrepullTimeout))
+}
+
+// newImagePullDaemonSet returns a DaemonSet that runs the given image on
+// every node matching the suite's node OS, which makes the kubelets there
+// pull it.
+func newImagePullDaemonSet(name, image string) *appsv1.DaemonSet {
+	labels := map[string]string{"name": name}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:            "puller",
+							Image:           image,
+							ImagePullPolicy: v1.PullIfNotPresent,
+						},
+					},
+				},
+			},
+		},
+	}
+	e2epod.SetNodeOSSelector(&ds.Spec.Template.Spec, e2epod.DefaultNodeOS)
+	return ds
+}
+
+// waitForImagesPulled waits until every pod of the image puller DaemonSets in
+// the given namespace has a container status proving its image reached the
+// node: once the kubelet has pulled the image it either runs the container or
+// records its exit, both of which fill in the status' ImageID.
+func waitForImagesPulled(c clientset.Interface, ns string, timeout time.Duration) error {
+	start := time.Now()
+	return wait.PollImmediate(10*time.Second, timeout, func() (bool, error) {
+		dsList, err := c.AppsV1().DaemonSets(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			framework.Logf("Error getting daemonsets in namespace: '%s': %v", ns, err)
+			if testutils.IsRetryableAPIError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		var desired int32
+		for _, ds := range dsList.Items {
+			if ds.Status.ObservedGeneration < ds.Generation {
+				framework.Logf("DaemonSet %s has not been observed by the controller yet", ds.Name)
+				return false, nil
+			}
+			desired += ds.Status.DesiredNumberScheduled
+		}
+
+		pods, err := c.CoreV1().Pods(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			framework.Logf("Error getting pods in namespace '%s': %v", ns, err)
+			if testutils.IsRetryableAPIError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		var pulled int32
+		for _, pod := range pods.Items {
+			done := len(pod.Status.ContainerStatuses) > 0
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.ImageID == "" && status.State.Running == nil && status.State.Terminated == nil {
+					done = false
+				}
+			}
+			if done {
+				pulled++
+			}
+		}
+		framework.Logf("%d / %d image puller pods have pulled their image (%d seconds elapsed)", pulled, desired, int(time.Since(start).Seconds()))
+		return pulled >= desired, nil
+	})
+}
+
 // setupSuite is the boilerplate that can be used to setup ginkgo test suites, on the SynchronizedBeforeSuite step.
 // There are certain operations we only want to run once per overall test invocation
 // (such as deleting old namespaces, or verifying that all system pods are running.
@@ -288,6 +440,14 @@ func setupSuite() {
 		framework.Logf("WARNING: Waiting for all daemonsets to be ready failed: %v", err)
 	}
 
+	if framework.TestContext.VerifyImageManifests {
+		verifyImageManifests(c)
+	}
+
+	if framework.TestContext.PrepullImages {
+		prepullImages(c)
+	}
+
 	// Log the version of the server and this client.
 	framework.Logf("e2e test version: %s", version.Get().GitVersion)
 
@@ -305,6 +465,12 @@ func setupSuite() {
 		nodeKiller := framework.NewNodeKiller(framework.TestContext.NodeKiller, c, framework.TestContext.Provider)
 		go nodeKiller.Run(framework.TestContext.NodeKiller.NodeKillerStopCh)
 	}
+
+	if framework.TestContext.Disruption.Enabled {
+		framework.TestContext.Disruption.DisruptionStopCh = make(chan struct{})
+		injector := framework.NewDisruptionInjector(framework.TestContext.Disruption, c, framework.TestContext.Provider)
+		go injector.Run(framework.TestContext.Disruption.DisruptionStopCh)
+	}
 }
 
 // logClusterImageSources writes out cluster image sources.
@@ -430,4 +596,25 @@ func setupSuitePerGinkgoNode() {
 	}
 	framework.TestContext.IPFamily = getDefaultClusterIPFamily(c)
 	framework.Logf("Cluster IP family: %s", framework.TestContext.IPFamily)
+
+	setupNodeExecutor(c)
+}
+
+// setupNodeExecutor installs the SSH-less node access transport when
+// requested, so helpers that run node commands work on clusters where SSH to
+// nodes is impossible, e.g. when the suite runs as a pod in the cluster.
+func setupNodeExecutor(c clientset.Interface) {
+	switch framework.TestContext.NodeExecMode {
+	case "", "ssh":
+	case "pod":
+		config, err := framework.LoadConfig()
+		if err != nil {
+			klog.Fatal("Error loading config for node executor: ", err)
+		}
+		executor := e2essh.NewHostPodExecutor(c, config, metav1.NamespaceSystem)
+		e2essh.SetNodeExecutor(executor)
+		framework.AddCleanupAction(executor.Cleanup)
+	default:
+		framework.Failf("Unknown --node-exec-mode %q, expected \"ssh\" or \"pod\"", framework.TestContext.NodeExecMode)
+	}
 }
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	e2emetrics "k8s.io/kubernetes/test/e2e/framework/metrics"
+)
+
+// apiLatencyCollector accumulates client-observed request latencies per
+// verb/resource over the whole run, so that any e2e run can double as an API
+// latency probe. One collector serves all specs; clients derived from the
+// framework configs report into it when --record-api-latency is set.
+type apiLatencyCollector struct {
+	lock    sync.Mutex
+	samples map[apiCallKey][]time.Duration
+}
+
+// apiCallKey identifies one latency bucket in the collector.
+type apiCallKey struct {
+	verb        string
+	resource    string
+	subresource string
+	scope       string
+}
+
+var apiLatency = &apiLatencyCollector{samples: map[apiCallKey][]time.Duration{}}
+
+// wrap is a transport.WrapperFunc which measures through this collector.
+func (c *apiLatencyCollector) wrap(rt http.RoundTripper) http.RoundTripper {
+	return &apiLatencyTransport{delegate: rt, collector: c}
+}
+
+func (c *apiLatencyCollector) record(key apiCallKey, latency time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.samples[key] = append(c.samples[key], latency)
+}
+
+type apiLatencyTransport struct {
+	delegate  http.RoundTripper
+	collector *apiLatencyCollector
+}
+
+func (t *apiLatencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Watches stay open for their whole lifetime and would dwarf every
+	// request/response latency, so they are not measured.
+	if req.URL.Query().Get("watch") == "true" {
+		return t.delegate.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.delegate.RoundTrip(req)
+	if err == nil {
+		t.collector.record(parseAPICallKey(req.Method, req.URL.Path), time.Since(start))
+	}
+	return resp, err
+}
+
+// parseAPICallKey derives the latency bucket from the request method and
+// path, mirroring how the apiserver labels its own metrics: LIST is a GET on
+// a collection, and the scope is resource, namespace or cluster.
+func parseAPICallKey(method, path string) apiCallKey {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	// Strip the group/version prefix: /api/v1 or /apis/<group>/<version>.
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return apiCallKey{verb: method, resource: path}
+	}
+
+	key := apiCallKey{scope: "cluster"}
+	if len(segments) >= 2 && segments[0] == "namespaces" {
+		// A GET on /namespaces/<name> addresses the namespace object
+		// itself, everything longer addresses something inside it.
+		if len(segments) > 2 {
+			key.scope = "namespace"
+			segments = segments[2:]
+		}
+	}
+	var name string
+	switch len(segments) {
+	case 0:
+		return apiCallKey{verb: method, resource: path}
+	case 1:
+		key.resource = segments[0]
+	case 2:
+		key.resource, name = segments[0], segments[1]
+	default:
+		key.resource, name = segments[0], segments[1]
+		key.subresource = segments[2]
+	}
+	if name != "" {
+		key.scope = "resource"
+	}
+
+	key.verb = method
+	if method == "GET" && name == "" {
+		key.verb = "LIST"
+	}
+	return key
+}
+
+// apiResponsivenessSummary aggregates the collected samples into the
+// perf-dash-compatible APIResponsiveness summary, percentiles included.
+func (c *apiLatencyCollector) apiResponsivenessSummary() *e2emetrics.APIResponsiveness {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	summary := &e2emetrics.APIResponsiveness{}
+	for key, samples := range c.samples {
+		sorted := make([]time.Duration, len(samples))
+		copy(sorted, samples)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		summary.APICalls = append(summary.APICalls, e2emetrics.APICall{
+			Resource:    key.resource,
+			Subresource: key.subresource,
+			Verb:        key.verb,
+			Scope:       key.scope,
+			Count:       len(sorted),
+			Latency: e2emetrics.LatencyMetric{
+				Perc50:  sorted[len(sorted)*50/100],
+				Perc90:  sorted[len(sorted)*90/100],
+				Perc99:  sorted[len(sorted)*99/100],
+				Perc100: sorted[len(sorted)-1],
+			},
+		})
+	}
+	sort.Sort(sort.Reverse(summary))
+	return summary
+}
+
+// SummarizeAPIResponsiveness prints or writes the client-observed API
+// latency summary collected over the run when --record-api-latency is set.
+// It is meant to run once, from the suite's after-suite actions.
+func SummarizeAPIResponsiveness() {
+	if !TestContext.RecordAPILatency {
+		return
+	}
+	printSummaries([]TestDataSummary{apiLatency.apiResponsivenessSummary()}, "e2e-suite")
+}
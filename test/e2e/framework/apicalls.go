@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// apiCallRecorder counts every verb+path the spec's clients send, so the
+// endpoints a spec touches can be accounted for in its report.
+type apiCallRecorder struct {
+	lock  sync.Mutex
+	calls map[string]int
+}
+
+func newAPICallRecorder() *apiCallRecorder {
+	return &apiCallRecorder{calls: map[string]int{}}
+}
+
+// wrap is a transport.WrapperFunc which records through this recorder. All
+// clients derived from the same framework config share one recorder.
+func (r *apiCallRecorder) wrap(rt http.RoundTripper) http.RoundTripper {
+	return &apiCallRecordingTransport{delegate: rt, recorder: r}
+}
+
+func (r *apiCallRecorder) record(verb, path string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls[verb+" "+path]++
+}
+
+// summary returns one "count verb path" line per distinct call, sorted by path.
+func (r *apiCallRecorder) summary() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	keys := make([]string, 0, len(r.calls))
+	for key := range r.calls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var lines []string
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%5d %s", r.calls[key], key))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type apiCallRecordingTransport struct {
+	delegate http.RoundTripper
+	recorder *apiCallRecorder
+}
+
+func (t *apiCallRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The query string is dropped to keep watches, selectors and resource
+	// versions from splitting one endpoint into many entries.
+	t.recorder.record(req.Method, req.URL.Path)
+	return t.delegate.RoundTrip(req)
+}
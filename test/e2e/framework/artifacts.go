@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UploadArtifacts publishes the contents of reportDir to target. A target
+// starting with http:// or https:// receives a single gzipped tar archive of
+// the directory via HTTP PUT, e.g. a pre-signed object store URL. Any other
+// target is treated as a directory, e.g. the mount point of a PVC, and the
+// report files are copied into it. This lets runs started as a Job inside
+// the cluster preserve their artifacts even though the pod filesystem is
+// ephemeral.
+func UploadArtifacts(reportDir, target string) error {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return uploadArtifactsHTTP(reportDir, target)
+	}
+	return copyArtifacts(reportDir, target)
+}
+
+// copyArtifacts copies all regular files under reportDir into the target
+// directory, preserving relative paths.
+func copyArtifacts(reportDir, target string) error {
+	return filepath.Walk(reportDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(reportDir, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(target, relPath)
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dest, err := os.Create(destPath)
+		if err != nil {
+			return err
+		}
+		defer dest.Close()
+		_, err = io.Copy(dest, src)
+		return err
+	})
+}
+
+// uploadArtifactsHTTP streams a gzipped tar archive of reportDir to the URL
+// with an HTTP PUT.
+func uploadArtifactsHTTP(reportDir, url string) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeArtifactsTar(reportDir, writer))
+	}()
+
+	req, err := http.NewRequest(http.MethodPut, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("uploading artifacts to %v returned status %q", url, resp.Status)
+	}
+	return nil
+}
+
+// writeArtifactsTar writes all regular files under reportDir as a gzipped
+// tar archive with paths relative to reportDir.
+func writeArtifactsTar(reportDir string, w io.Writer) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	err := filepath.Walk(reportDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(reportDir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = relPath
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package auditlog verifies that actions taken by a spec produced the
+// expected apiserver audit entries, regardless of whether the cluster logs
+// them to a file or to a webhook sink deployed by the test.
+package auditlog
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	"k8s.io/kubernetes/test/utils"
+)
+
+// Source yields a stream of audit events encoded as JSON lines.
+type Source interface {
+	// Stream returns a fresh reader over all audit events produced so far.
+	// It is called once per poll attempt.
+	Stream() (io.ReadCloser, error)
+}
+
+// apiserverLogSource tails an audit log file through the apiserver's /logs
+// endpoint, for clusters using the log backend.
+type apiserverLogSource struct {
+	c       clientset.Interface
+	logName string
+}
+
+// NewAPIServerLogSource returns a source reading the named audit log file,
+// typically "kube-apiserver-audit.log", through the apiserver's /logs
+// endpoint.
+func NewAPIServerLogSource(c clientset.Interface, logName string) Source {
+	return &apiserverLogSource{c: c, logName: logName}
+}
+
+func (s *apiserverLogSource) Stream() (io.ReadCloser, error) {
+	return s.c.CoreV1().RESTClient().Get().AbsPath("/logs/" + s.logName).Stream(context.TODO())
+}
+
+// podLogSource reads audit events from the logs of a webhook sink pod
+// deployed by the test, such as the agnhost audit-proxy.
+type podLogSource struct {
+	c             clientset.Interface
+	namespace     string
+	podName       string
+	containerName string
+}
+
+// NewPodLogSource returns a source reading audit events from the given
+// container's logs.
+func NewPodLogSource(c clientset.Interface, namespace, podName, containerName string) Source {
+	return &podLogSource{c: c, namespace: namespace, podName: podName, containerName: containerName}
+}
+
+func (s *podLogSource) Stream() (io.ReadCloser, error) {
+	logs, err := e2epod.GetPodLogs(s.c, s.namespace, s.podName, s.containerName)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(strings.NewReader(logs)), nil
+}
+
+// Checker polls a source until all expected audit events are observed.
+type Checker struct {
+	source Source
+	// Version is the audit API version the events are encoded with.
+	Version schema.GroupVersion
+	// PollInterval and PollTimeout bound the polling. The default flush
+	// interval of the log backend is 30 seconds, so the defaults retry every
+	// 30 seconds for 5 minutes.
+	PollInterval time.Duration
+	PollTimeout  time.Duration
+}
+
+// NewChecker returns a checker for the source with default version and polling.
+func NewChecker(source Source) *Checker {
+	return &Checker{
+		source:       source,
+		Version:      auditv1.SchemeGroupVersion,
+		PollInterval: 30 * time.Second,
+		PollTimeout:  5 * time.Minute,
+	}
+}
+
+// ExpectEvents fails the spec unless every expected event shows up in the
+// source before the poll timeout, logging the missing-events report of each
+// attempt along the way.
+func (c *Checker) ExpectEvents(expected []utils.AuditEvent) {
+	err := wait.Poll(c.PollInterval, c.PollTimeout, func() (bool, error) {
+		stream, err := c.source.Stream()
+		if err != nil {
+			framework.Logf("Failed to read audit events: %v", err)
+			return false, nil
+		}
+		defer stream.Close()
+		missingReport, err := utils.CheckAuditLines(stream, expected, c.Version)
+		if err != nil {
+			framework.Logf("Failed to observe audit events: %v", err)
+		} else if len(missingReport.MissingEvents) > 0 {
+			framework.Logf(missingReport.String())
+		}
+		return err == nil && len(missingReport.MissingEvents) == 0, nil
+	})
+	framework.ExpectNoError(err, "after %v failed to observe audit events", c.PollTimeout)
+}
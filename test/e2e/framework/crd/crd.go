@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crd provides helpers for bootstrapping CustomResourceDefinitions
+// and their custom resources from YAML fixtures, and for unstructured CRUD
+// on them through the dynamic client with retry on transient API errors.
+package crd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	crdclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+	testutils "k8s.io/kubernetes/test/utils"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// poll is how often to poll while waiting for a CRD or resource state.
+	poll = 2 * time.Second
+	// establishedTimeout is how long to wait for a created CRD to become
+	// established and start serving its resource.
+	establishedTimeout = 2 * time.Minute
+)
+
+// CreateFromManifest decodes a CustomResourceDefinition from a YAML or JSON
+// manifest, creates it and waits until the CRD is established, returning the
+// CRD as persisted.
+func CreateFromManifest(client crdclientset.Interface, manifest []byte) (*apiextensionsv1.CustomResourceDefinition, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(manifest, crd); err != nil {
+		return nil, fmt.Errorf("error decoding CRD manifest: %v", err)
+	}
+	crd, err := client.ApiextensionsV1().CustomResourceDefinitions().Create(context.TODO(), crd, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error creating CRD %q: %v", crd.Name, err)
+	}
+	if err := WaitForEstablished(client, crd.Name, establishedTimeout); err != nil {
+		return nil, err
+	}
+	return client.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), crd.Name, metav1.GetOptions{})
+}
+
+// WaitForEstablished waits until the named CRD has the Established condition
+// set to true, i.e. until the apiserver serves the CRD's resource.
+func WaitForEstablished(client crdclientset.Interface, name string, timeout time.Duration) error {
+	return wait.PollImmediate(poll, timeout, func() (bool, error) {
+		crd, err := client.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			if testutils.IsRetryableAPIError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		for _, cond := range crd.Status.Conditions {
+			if cond.Type == apiextensionsv1.Established && cond.Status == apiextensionsv1.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// GVRForCRD returns the GroupVersionResource the CRD serves for the given
+// version, which a dynamic client needs to address the custom resources.
+func GVRForCRD(crd *apiextensionsv1.CustomResourceDefinition, version string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    crd.Spec.Group,
+		Version:  version,
+		Resource: crd.Spec.Names.Plural,
+	}
+}
+
+// ResourceFromManifest decodes a custom resource from a YAML or JSON
+// manifest into an unstructured object.
+func ResourceFromManifest(manifest []byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(manifest, &obj.Object); err != nil {
+		return nil, fmt.Errorf("error decoding custom resource manifest: %v", err)
+	}
+	return obj, nil
+}
+
+// CreateResource creates the unstructured custom resource, retrying on
+// transient API errors as an apiserver with a freshly established CRD may
+// briefly return them.
+func CreateResource(client dynamic.ResourceInterface, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	var created *unstructured.Unstructured
+	err := wait.PollImmediate(poll, establishedTimeout, func() (bool, error) {
+		var err error
+		created, err = client.Create(context.TODO(), obj, metav1.CreateOptions{})
+		if err != nil {
+			if testutils.IsRetryableAPIError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating custom resource %q: %v", obj.GetName(), err)
+	}
+	return created, nil
+}
+
+// CreateResourceFromManifest decodes a custom resource from a YAML or JSON
+// manifest and creates it through the dynamic client.
+func CreateResourceFromManifest(client dynamic.ResourceInterface, manifest []byte) (*unstructured.Unstructured, error) {
+	obj, err := ResourceFromManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return CreateResource(client, obj)
+}
+
+// UpdateResource gets the named custom resource, applies the update function
+// to it and writes it back, retrying the whole cycle on conflicts.
+func UpdateResource(client dynamic.ResourceInterface, name string, update func(*unstructured.Unstructured)) (*unstructured.Unstructured, error) {
+	var updated *unstructured.Unstructured
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		update(obj)
+		updated, err = client.Update(context.TODO(), obj, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error updating custom resource %q: %v", name, err)
+	}
+	return updated, nil
+}
+
+// DeleteResource deletes the named custom resource and waits until it is
+// gone. Deleting a resource that does not exist is not an error.
+func DeleteResource(client dynamic.ResourceInterface, name string) error {
+	if err := client.Delete(context.TODO(), name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting custom resource %q: %v", name, err)
+	}
+	return wait.PollImmediate(poll, establishedTimeout, func() (bool, error) {
+		_, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil && !testutils.IsRetryableAPIError(err) {
+			return false, err
+		}
+		return false, nil
+	})
+}
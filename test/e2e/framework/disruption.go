@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	e2essh "k8s.io/kubernetes/test/e2e/framework/ssh"
+)
+
+// DisruptionInjector is a utility to inject failures while a suite runs, so
+// resilience-focused suites can exercise recovery paths against the same
+// framework instead of bespoke scripts. It can run on its own jittered
+// interval via Run, or a suite can call Disrupt directly between steps.
+type DisruptionInjector struct {
+	config   DisruptionConfig
+	client   clientset.Interface
+	provider string
+}
+
+// NewDisruptionInjector creates a new DisruptionInjector.
+func NewDisruptionInjector(config DisruptionConfig, client clientset.Interface, provider string) *DisruptionInjector {
+	return &DisruptionInjector{config, client, provider}
+}
+
+// Run injects disruptions until stopCh is closed.
+func (d *DisruptionInjector) Run(stopCh <-chan struct{}) {
+	// wait.JitterUntil starts work immediately, so wait first.
+	time.Sleep(wait.Jitter(d.config.Interval, d.config.JitterFactor))
+	wait.JitterUntil(func() {
+		if err := d.Disrupt(); err != nil {
+			Logf("ERROR while injecting disruption: %v", err)
+		}
+	}, d.config.Interval, d.config.JitterFactor, true, stopCh)
+}
+
+// Disrupt injects one disruption, picked at random from the enabled kinds.
+func (d *DisruptionInjector) Disrupt() error {
+	type disruption struct {
+		name string
+		do   func() error
+	}
+	var disruptions []disruption
+	if d.config.RestartAPIServer {
+		disruptions = append(disruptions, disruption{"restarting kube-apiserver", d.restartAPIServer})
+	}
+	if d.config.KillTestPods {
+		disruptions = append(disruptions, disruption{"killing a random test pod", d.killRandomTestPod})
+	}
+	if d.config.CordonNodes {
+		disruptions = append(disruptions, disruption{"cordoning a random node", d.cordonRandomNode})
+	}
+	if len(disruptions) == 0 {
+		return fmt.Errorf("no disruption kinds enabled")
+	}
+	picked := disruptions[rand.Intn(len(disruptions))]
+	Logf("Injecting disruption: %v", picked.name)
+	return picked.do()
+}
+
+// restartAPIServer kills the kube-apiserver process on the master so that it
+// gets restarted by its supervisor.
+func (d *DisruptionInjector) restartAPIServer() error {
+	// TODO: Make it work for all providers and distros.
+	if !ProviderIs("gce", "aws") {
+		return fmt.Errorf("unsupported provider for restarting kube-apiserver: %s", d.provider)
+	}
+	cmd := "pidof kube-apiserver | xargs sudo kill"
+	Logf("Restarting kube-apiserver via ssh, running: %v", cmd)
+	result, err := e2essh.SSH(cmd, net.JoinHostPort(GetMasterHost(), e2essh.SSHPort), d.provider)
+	if err != nil || result.Code != 0 {
+		e2essh.LogResult(result)
+		return fmt.Errorf("couldn't restart kube-apiserver: %v", err)
+	}
+	return nil
+}
+
+// killRandomTestPod force-deletes one randomly picked running pod from the
+// namespaces this run created, identified by their e2e-run label.
+func (d *DisruptionInjector) killRandomTestPod() error {
+	namespaces, err := d.client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("e2e-run=%v", RunID),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't list test namespaces: %v", err)
+	}
+	var candidates []v1.Pod
+	for _, namespace := range namespaces.Items {
+		pods, err := d.client.CoreV1().Pods(namespace.Name).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("couldn't list pods in namespace %v: %v", namespace.Name, err)
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == v1.PodRunning {
+				candidates = append(candidates, pod)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		Logf("No running test pods to kill")
+		return nil
+	}
+	pod := candidates[rand.Intn(len(candidates))]
+	Logf("Killing pod %v/%v", pod.Namespace, pod.Name)
+	return d.client.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, *metav1.NewDeleteOptions(0))
+}
+
+// cordonRandomNode cordons one randomly picked schedulable node and uncordons
+// it again after the configured duration.
+func (d *DisruptionInjector) cordonRandomNode() error {
+	nodes, err := e2enode.GetReadySchedulableNodes(d.client)
+	if err != nil {
+		return err
+	}
+	name := nodes.Items[rand.Intn(len(nodes.Items))].Name
+	Logf("Cordoning node %v for %v", name, d.config.CordonDuration)
+	if err := d.setNodeUnschedulable(name, true); err != nil {
+		return err
+	}
+	time.Sleep(d.config.CordonDuration)
+	Logf("Uncordoning node %v", name)
+	return d.setNodeUnschedulable(name, false)
+}
+
+func (d *DisruptionInjector) setNodeUnschedulable(name string, unschedulable bool) error {
+	return wait.PollImmediate(Poll, 2*time.Minute, func() (bool, error) {
+		node, err := d.client.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		node.Spec.Unschedulable = unschedulable
+		if _, err := d.client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{}); err != nil {
+			Logf("Error while updating node %v: %v", name, err)
+			return false, nil
+		}
+		return true, nil
+	})
+}
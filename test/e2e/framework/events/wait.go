@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// EventMatcher selects events by involved object, reason and message. Empty
+// fields match anything, so a zero matcher matches every event.
+type EventMatcher struct {
+	// InvolvedObjectName and InvolvedObjectKind restrict the object the
+	// event is about.
+	InvolvedObjectName string
+	InvolvedObjectKind string
+	// Reason is compared verbatim, e.g. "FailedScheduling".
+	Reason string
+	// MessagePattern is a regular expression matched against the message.
+	MessagePattern string
+}
+
+// Matches reports whether the event satisfies all set fields of the matcher.
+func (m EventMatcher) Matches(event *v1.Event) bool {
+	if m.InvolvedObjectName != "" && event.InvolvedObject.Name != m.InvolvedObjectName {
+		return false
+	}
+	if m.InvolvedObjectKind != "" && event.InvolvedObject.Kind != m.InvolvedObjectKind {
+		return false
+	}
+	if m.Reason != "" && event.Reason != m.Reason {
+		return false
+	}
+	if m.MessagePattern != "" && !regexp.MustCompile(m.MessagePattern).MatchString(event.Message) {
+		return false
+	}
+	return true
+}
+
+// String describes the matcher for error messages.
+func (m EventMatcher) String() string {
+	var parts []string
+	if m.InvolvedObjectKind != "" {
+		parts = append(parts, "kind="+m.InvolvedObjectKind)
+	}
+	if m.InvolvedObjectName != "" {
+		parts = append(parts, "involvedObject="+m.InvolvedObjectName)
+	}
+	if m.Reason != "" {
+		parts = append(parts, "reason="+m.Reason)
+	}
+	if m.MessagePattern != "" {
+		parts = append(parts, "message~"+m.MessagePattern)
+	}
+	if len(parts) == 0 {
+		return "any event"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WaitForEvent watches the namespace's events until one matches m, returning
+// the matching event. On timeout the error lists the events that were
+// observed instead, so the failure shows what actually happened nearby.
+func WaitForEvent(c clientset.Interface, namespace string, m EventMatcher, timeout time.Duration) (*v1.Event, error) {
+	lw := cache.NewListWatchFromClient(c.CoreV1().RESTClient(), "events", namespace, fields.Everything())
+	var observed []string
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), timeout)
+	defer cancel()
+	lastEvent, err := watchtools.UntilWithSync(ctx, lw, &v1.Event{}, nil, func(we watch.Event) (bool, error) {
+		switch we.Type {
+		case watch.Added, watch.Modified:
+			event, ok := we.Object.(*v1.Event)
+			if !ok {
+				return false, fmt.Errorf("unexpected object type %T while watching events", we.Object)
+			}
+			observed = append(observed, formatEvent(event))
+			return m.Matches(event), nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		if err == wait.ErrWaitTimeout {
+			return nil, fmt.Errorf("timed out after %v waiting for an event matching %q in namespace %s, observed events:\n%s",
+				timeout, m, namespace, strings.Join(observed, "\n"))
+		}
+		return nil, err
+	}
+	return lastEvent.Object.(*v1.Event), nil
+}
+
+// ObserveEventAfterAction performs action and then waits for a matching
+// event, failing with the observed events when none arrives in time.
+func ObserveEventAfterAction(c clientset.Interface, namespace string, m EventMatcher, timeout time.Duration, action func() error) (*v1.Event, error) {
+	if err := action(); err != nil {
+		return nil, err
+	}
+	return WaitForEvent(c, namespace, m, timeout)
+}
+
+// formatEvent renders one event the way the timeout error reports them.
+func formatEvent(event *v1.Event) string {
+	return fmt.Sprintf("%s %s/%s: %s: %s", event.Type, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason, event.Message)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fixture loads YAML manifest fixtures, substitutes per-test
+// variables into them and creates the objects through the dynamic client,
+// so tests don't have to hand-roll decoding and cleanup for every manifest
+// they carry. Manifests come from the registered testfiles sources (bindata
+// or the repository tree; this Go version predates go:embed) or, when
+// --manifest-path is set, from that directory instead.
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/framework/config"
+	"k8s.io/kubernetes/test/e2e/framework/testfiles"
+)
+
+var manifestPath = config.Flags.String("manifest-path", "", "Directory to load manifest fixtures from instead of the compiled-in test files.")
+
+// Load returns the named manifest with the given substitutions applied.
+// Placeholders in the manifest use the form {{key}}.
+func Load(filePath string, substitutions map[string]string) ([]byte, error) {
+	var data []byte
+	var err error
+	if *manifestPath != "" {
+		data, err = ioutil.ReadFile(filepath.Join(*manifestPath, filePath))
+	} else {
+		data, err = testfiles.Read(filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content := string(data)
+	for key, value := range substitutions {
+		content = strings.Replace(content, "{{"+key+"}}", value, -1)
+	}
+	if rest := strings.Index(content, "{{"); rest >= 0 {
+		return nil, fmt.Errorf("manifest %q contains an unsubstituted placeholder near %q", filePath, strings.SplitN(content[rest:], "\n", 2)[0])
+	}
+	return []byte(content), nil
+}
+
+// CreateFromManifest loads the named manifest, substitutes the given
+// variables plus {{namespace}} for the test's namespace, and creates every
+// document in it through the dynamic client. Namespaced objects without a
+// namespace of their own go into the test's namespace and disappear with
+// it; cluster-scoped objects get an AfterEach registered that deletes them.
+// The created objects are returned in manifest order.
+//
+// Image URLs should be substituted in from imageutils.GetE2EImage, which
+// keeps fixtures working when the image registries are replaced with
+// mirrors.
+func CreateFromManifest(f *framework.Framework, filePath string, substitutions map[string]string) ([]*unstructured.Unstructured, error) {
+	withNamespace := map[string]string{"namespace": f.Namespace.Name}
+	for key, value := range substitutions {
+		withNamespace[key] = value
+	}
+	data, err := Load(filePath, withNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := decodeObjects(data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding manifest %q: %v", filePath, err)
+	}
+
+	groupResources, err := restmapper.GetAPIGroupResources(f.ClientSet.Discovery())
+	if err != nil {
+		return nil, err
+	}
+	restMapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	var created []*unstructured.Unstructured
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return created, fmt.Errorf("no resource mapping for %s in manifest %q: %v", gvk, filePath, err)
+		}
+
+		var client dynamic.ResourceInterface = f.DynamicClient.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace := obj.GetNamespace()
+			if namespace == "" {
+				namespace = f.Namespace.Name
+			}
+			client = f.DynamicClient.Resource(mapping.Resource).Namespace(namespace)
+		} else {
+			registerClusterScopedCleanup(f, mapping.Resource, obj.GetName())
+		}
+
+		item, err := client.Create(context.TODO(), obj, metav1.CreateOptions{})
+		if err != nil {
+			return created, fmt.Errorf("error creating %s %q from manifest %q: %v", gvk.Kind, obj.GetName(), filePath, err)
+		}
+		created = append(created, item)
+	}
+	return created, nil
+}
+
+// registerClusterScopedCleanup arranges for a cluster-scoped object, which
+// does not go away with the test namespace, to be deleted after the test.
+func registerClusterScopedCleanup(f *framework.Framework, gvr schema.GroupVersionResource, name string) {
+	f.AddAfterEach(fmt.Sprintf("fixture/%s/%s", gvr.Resource, name), func(f *framework.Framework, failed bool) {
+		err := f.DynamicClient.Resource(gvr).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			framework.Logf("Error deleting fixture %s %q: %v", gvr.Resource, name, err)
+		}
+	})
+}
+
+// decodeObjects splits the manifest into YAML documents and decodes each
+// into an unstructured object, skipping empty documents.
+func decodeObjects(data []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return objs, nil
+			}
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+}
@@ -47,6 +47,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	scaleclient "k8s.io/client-go/scale"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
@@ -84,7 +85,8 @@ type Framework struct {
 	NamespaceDeletionTimeout time.Duration
 	SkipPrivilegedPSPBinding bool // Whether to skip creating a binding to the privileged PSP in the test namespace
 
-	gatherer *ContainerResourceGatherer
+	gatherer        *ContainerResourceGatherer
+	testPodGatherer *ContainerResourceGatherer
 	// Constraints that passed to a check which is executed after data is gathered to
 	// see if 99% of results are within acceptable bounds. It has to be injected in the test,
 	// as expectations vary greatly. Constraints are grouped by the container names.
@@ -107,9 +109,24 @@ type Framework struct {
 	// the various afterEaches
 	afterEaches map[string]AfterEachActionFunc
 
+	// NamespaceLabels and NamespaceAnnotations are merged into every namespace
+	// the framework creates. The framework's BeforeEach runs before any spec
+	// BeforeEach declared after NewDefaultFramework, so vary them per spec by
+	// setting them at Describe level or in a BeforeEach declared earlier.
+	NamespaceLabels      map[string]string
+	NamespaceAnnotations map[string]string
+
 	// beforeEachStarted indicates that BeforeEach has started
 	beforeEachStarted bool
 
+	// apiCallRecorder tracks the endpoints this spec's clients touch when
+	// --record-api-calls is set.
+	apiCallRecorder *apiCallRecorder
+
+	// rateLimiter throttles this spec's clients and tracks time spent waiting
+	// in client-side rate limiting.
+	rateLimiter *throttleTrackingRateLimiter
+
 	// configuration for framework's client
 	Options Options
 
@@ -145,6 +162,12 @@ func NewDefaultFramework(baseName string) *Framework {
 		ClientQPS:   20,
 		ClientBurst: 50,
 	}
+	if TestContext.ClientQPS > 0 {
+		options.ClientQPS = float32(TestContext.ClientQPS)
+	}
+	if TestContext.ClientBurst > 0 {
+		options.ClientBurst = TestContext.ClientBurst
+	}
 	return NewFramework(baseName, options, nil)
 }
 
@@ -191,11 +214,26 @@ func (f *Framework) BeforeEach() {
 
 		config.QPS = f.Options.ClientQPS
 		config.Burst = f.Options.ClientBurst
+		f.rateLimiter = newThrottleTrackingRateLimiter(config.QPS, config.Burst)
+		config.RateLimiter = f.rateLimiter
 		if f.Options.GroupVersion != nil {
 			config.GroupVersion = f.Options.GroupVersion
 		}
 		if TestContext.KubeAPIContentType != "" {
 			config.ContentType = TestContext.KubeAPIContentType
+			if TestContext.KubeAPIContentType == runtime.ContentTypeProtobuf {
+				// CRD-backed resources are only served as JSON, so let
+				// protobuf clients negotiate down to JSON for them rather
+				// than failing.
+				config.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+			}
+		}
+		if TestContext.RecordAPICalls {
+			f.apiCallRecorder = newAPICallRecorder()
+			config.Wrap(f.apiCallRecorder.wrap)
+		}
+		if TestContext.RecordAPILatency {
+			config.Wrap(apiLatency.wrap)
 		}
 		f.clientConfig = rest.CopyConfig(config)
 		f.ClientSet, err = clientset.NewForConfig(config)
@@ -230,11 +268,26 @@ func (f *Framework) BeforeEach() {
 
 	if !f.SkipNamespaceCreation {
 		ginkgo.By(fmt.Sprintf("Building a namespace api object, basename %s", f.BaseName))
-		namespace, err := f.CreateNamespace(f.BaseName, map[string]string{
+		nsLabels := map[string]string{
 			"e2e-framework": f.BaseName,
-		})
+		}
+		for k, v := range f.NamespaceLabels {
+			nsLabels[k] = v
+		}
+		namespace, err := f.CreateNamespace(f.BaseName, nsLabels)
 		ExpectNoError(err)
 
+		if len(f.NamespaceAnnotations) > 0 {
+			if namespace.Annotations == nil {
+				namespace.Annotations = map[string]string{}
+			}
+			for k, v := range f.NamespaceAnnotations {
+				namespace.Annotations[k] = v
+			}
+			namespace, err = f.ClientSet.CoreV1().Namespaces().Update(context.TODO(), namespace, metav1.UpdateOptions{})
+			ExpectNoError(err)
+		}
+
 		f.Namespace = namespace
 
 		if TestContext.VerifyServiceAccount {
@@ -244,6 +297,13 @@ func (f *Framework) BeforeEach() {
 		} else {
 			Logf("Skipping waiting for service account")
 		}
+
+		if TestContext.ImagePullSecretFile != "" {
+			ginkgo.By("Creating an image pull secret in the namespace")
+			err = setupImagePullSecret(f.ClientSet, namespace.Name)
+			ExpectNoError(err)
+		}
+
 		f.UniqueName = f.Namespace.GetName()
 	} else {
 		// not guaranteed to be unique, but very likely
@@ -276,6 +336,16 @@ func (f *Framework) BeforeEach() {
 		}
 	}
 
+	if TestContext.GatherTestPodResourceUsageData && f.Namespace != nil {
+		var err error
+		f.testPodGatherer, err = NewTestPodResourceGatherer(f.ClientSet, f.Namespace.Name)
+		if err != nil {
+			Logf("Error while creating NewTestPodResourceGatherer: %v", err)
+		} else {
+			go f.testPodGatherer.StartGatheringData()
+		}
+	}
+
 	if TestContext.GatherLogsSizes {
 		f.logsSizeWaitGroup = sync.WaitGroup{}
 		f.logsSizeWaitGroup.Add(1)
@@ -289,7 +359,7 @@ func (f *Framework) BeforeEach() {
 
 	gatherMetricsAfterTest := TestContext.GatherMetricsAfterTest == "true" || TestContext.GatherMetricsAfterTest == "master"
 	if gatherMetricsAfterTest && TestContext.IncludeClusterAutoscalerMetrics {
-		grabber, err := e2emetrics.NewMetricsGrabber(f.ClientSet, f.KubemarkExternalClusterClientSet, !ProviderIs("kubemark"), false, false, false, TestContext.IncludeClusterAutoscalerMetrics)
+		grabber, err := e2emetrics.NewMetricsGrabber(f.ClientSet, f.KubemarkExternalClusterClientSet, f.clientConfig, !ProviderIs("kubemark"), false, false, false, TestContext.IncludeClusterAutoscalerMetrics)
 		if err != nil {
 			Logf("Failed to create MetricsGrabber (skipping ClusterAutoscaler metrics gathering before test): %v", err)
 		} else {
@@ -379,7 +449,9 @@ func (f *Framework) AfterEach() {
 		// Whether to delete namespace is determined by 3 factors: delete-namespace flag, delete-namespace-on-failure flag and the test result
 		// if delete-namespace set to false, namespace will always be preserved.
 		// if delete-namespace is true and delete-namespace-on-failure is false, namespace will be preserved if test failed.
-		if TestContext.DeleteNamespace && (TestContext.DeleteNamespaceOnFailure || !ginkgo.CurrentGinkgoTestDescription().Failed) {
+		if TestContext.ReuseNamespace || TestContext.SkipNamespaceDeletion {
+			Logf("Keeping test namespaces for inspection, skipping namespace deletion!")
+		} else if TestContext.DeleteNamespace && (TestContext.DeleteNamespaceOnFailure || !ginkgo.CurrentGinkgoTestDescription().Failed) {
 			for _, ns := range f.namespacesToDelete {
 				ginkgo.By(fmt.Sprintf("Destroying namespace %q for this suite.", ns.Name))
 				if err := f.ClientSet.CoreV1().Namespaces().Delete(context.TODO(), ns.Name, metav1.DeleteOptions{}); err != nil {
@@ -431,6 +503,17 @@ func (f *Framework) AfterEach() {
 		f.TestSummaries = append(f.TestSummaries, summary)
 	}
 
+	if f.testPodGatherer != nil {
+		ginkgo.By("Collecting test pod resource usage data")
+		summary, err := f.testPodGatherer.StopAndSummarize([]int{90, 99, 100}, nil)
+		if err != nil {
+			Logf("Error while summarizing test pod resource usage: %v", err)
+		} else {
+			f.TestSummaries = append(f.TestSummaries, &TestPodResourceUsageSummary{*summary})
+		}
+		f.testPodGatherer = nil
+	}
+
 	if TestContext.GatherLogsSizes {
 		ginkgo.By("Gathering log sizes data")
 		close(f.logsSizeCloseChannel)
@@ -442,7 +525,7 @@ func (f *Framework) AfterEach() {
 		ginkgo.By("Gathering metrics")
 		// Grab apiserver, scheduler, controller-manager metrics and (optionally) nodes' kubelet metrics.
 		grabMetricsFromKubelets := TestContext.GatherMetricsAfterTest != "master" && !ProviderIs("kubemark")
-		grabber, err := e2emetrics.NewMetricsGrabber(f.ClientSet, f.KubemarkExternalClusterClientSet, grabMetricsFromKubelets, true, true, true, TestContext.IncludeClusterAutoscalerMetrics)
+		grabber, err := e2emetrics.NewMetricsGrabber(f.ClientSet, f.KubemarkExternalClusterClientSet, f.clientConfig, grabMetricsFromKubelets, true, true, true, TestContext.IncludeClusterAutoscalerMetrics)
 		if err != nil {
 			Logf("Failed to create MetricsGrabber (skipping metrics gathering): %v", err)
 		} else {
@@ -457,6 +540,21 @@ func (f *Framework) AfterEach() {
 
 	TestContext.CloudConfig.Provider.FrameworkAfterEach(f)
 
+	// Attach the endpoints this spec touched to its captured output.
+	if f.apiCallRecorder != nil {
+		Logf("API calls made during this spec:\n%s", f.apiCallRecorder.summary())
+		f.apiCallRecorder = nil
+	}
+
+	// Surface time lost to client-side rate limiting, which otherwise only
+	// shows up as a slow spec.
+	if f.rateLimiter != nil {
+		if throttled := f.rateLimiter.throttledTime(); throttled > 0 {
+			Logf("Spec spent %v waiting in client-side throttling (QPS %v, burst %d)", throttled.Round(time.Millisecond), f.Options.ClientQPS, f.Options.ClientBurst)
+		}
+		f.rateLimiter = nil
+	}
+
 	// Report any flakes that were observed in the e2e test and reset.
 	if f.flakeReport != nil && f.flakeReport.GetFlakeCount() > 0 {
 		f.TestSummaries = append(f.TestSummaries, f.flakeReport)
@@ -491,6 +589,45 @@ func (f *Framework) CreateNamespace(baseName string, labels map[string]string) (
 	return ns, err
 }
 
+// imagePullSecretName is the name of the secret setupImagePullSecret creates
+// in each test namespace from TestContext.ImagePullSecretFile.
+const imagePullSecretName = "e2e-image-pull-secret"
+
+// setupImagePullSecret creates an image pull secret from
+// TestContext.ImagePullSecretFile in the given namespace and attaches it to
+// the default service account there, so pods created by the tests can pull
+// images from mirrors that require authentication.
+func setupImagePullSecret(c clientset.Interface, namespace string) error {
+	content, err := ioutil.ReadFile(TestContext.ImagePullSecretFile)
+	if err != nil {
+		return fmt.Errorf("error reading image pull secret file %q: %v", TestContext.ImagePullSecretFile, err)
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: imagePullSecretName,
+		},
+		Type: v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: content,
+		},
+	}
+	if _, err := c.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating image pull secret in namespace %q: %v", namespace, err)
+	}
+	if err := WaitForDefaultServiceAccountInNamespace(c, namespace); err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sa, err := c.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), "default", metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		sa.ImagePullSecrets = append(sa.ImagePullSecrets, v1.LocalObjectReference{Name: imagePullSecretName})
+		_, err = c.CoreV1().ServiceAccounts(namespace).Update(context.TODO(), sa, metav1.UpdateOptions{})
+		return err
+	})
+}
+
 // RecordFlakeIfError records flakeness info if error happens.
 // NOTE: This function is not used at any places yet, but we are in progress for https://github.com/kubernetes/kubernetes/issues/66239 which requires this. Please don't remove this.
 func (f *Framework) RecordFlakeIfError(err error, optionalDescription ...interface{}) {
@@ -586,6 +723,32 @@ func (kc *KubeConfig) FindCluster(name string) *KubeCluster {
 	return nil
 }
 
+// Namespace label keys read by pod security admission. Clusters without that
+// admission plugin ignore them, so they are always safe to set.
+const (
+	PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	PodSecurityWarnLabel    = "pod-security.kubernetes.io/warn"
+	PodSecurityAuditLabel   = "pod-security.kubernetes.io/audit"
+)
+
+// SetPodSecurityLevels records pod security enforce/warn/audit levels to set
+// as labels on the namespaces created for this framework's specs. Empty
+// levels are left unset, so mixed-privilege suites can share one framework.
+func (f *Framework) SetPodSecurityLevels(enforce, warn, audit string) {
+	if f.NamespaceLabels == nil {
+		f.NamespaceLabels = map[string]string{}
+	}
+	for label, level := range map[string]string{
+		PodSecurityEnforceLabel: enforce,
+		PodSecurityWarnLabel:    warn,
+		PodSecurityAuditLabel:   audit,
+	} {
+		if level != "" {
+			f.NamespaceLabels[label] = level
+		}
+	}
+}
+
 // KubeDescribe is wrapper function for ginkgo describe.  Adds namespacing.
 // TODO: Support type safe tagging as well https://github.com/kubernetes/kubernetes/pull/22401.
 func KubeDescribe(text string, body func()) bool {
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubectl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	uexec "k8s.io/utils/exec"
+	"sigs.k8s.io/yaml"
+
+	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
+)
+
+// Result captures everything one kubectl invocation produced, so tests can
+// assert on structured fields instead of string-matching raw output.
+type Result struct {
+	Stdout string
+	Stderr string
+	// Warnings are the warnings kubectl printed to stderr, one entry per
+	// warning with the "Warning:" prefix stripped.
+	Warnings []string
+	// Code is the exit code kubectl terminated with.
+	Code int
+}
+
+// Run runs kubectl with the given arguments, piping stdin into it when
+// non-empty, and returns the captured output, warnings and exit code. When
+// kubectl exits non-zero the Result still carries everything it printed and
+// the returned error is a uexec.CodeExitError wrapping the exit code.
+func (tk *TestKubeconfig) Run(stdin string, args ...string) (Result, error) {
+	cmd := tk.KubectlCmd(args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	e2elog.Logf("Running '%s %s'", cmd.Path, strings.Join(cmd.Args[1:], " ")) // skip arg[0] as it is printed separately
+	err := cmd.Run()
+	result := Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Warnings: parseWarnings(stderr.String()),
+	}
+	if err != nil {
+		result.Code = 127
+		if ee, ok := err.(*exec.ExitError); ok {
+			result.Code = ee.Sys().(syscall.WaitStatus).ExitStatus()
+		}
+		return result, uexec.CodeExitError{
+			Err:  fmt.Errorf("error running %v:\nCommand stdout:\n%v\nstderr:\n%v\nerror:\n%v", cmd, result.Stdout, result.Stderr, err),
+			Code: result.Code,
+		}
+	}
+	return result, nil
+}
+
+// RunJSON runs kubectl with "-o json" appended to the arguments and
+// unmarshals its stdout into obj, typically a pointer to an API object.
+func (tk *TestKubeconfig) RunJSON(stdin string, obj interface{}, args ...string) (Result, error) {
+	result, err := tk.Run(stdin, append(args, "-o", "json")...)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal([]byte(result.Stdout), obj); err != nil {
+		return result, fmt.Errorf("error unmarshalling kubectl JSON output into %T: %v", obj, err)
+	}
+	return result, nil
+}
+
+// RunYAML runs kubectl with "-o yaml" appended to the arguments and
+// unmarshals its stdout into obj, typically a pointer to an API object.
+func (tk *TestKubeconfig) RunYAML(stdin string, obj interface{}, args ...string) (Result, error) {
+	result, err := tk.Run(stdin, append(args, "-o", "yaml")...)
+	if err != nil {
+		return result, err
+	}
+	if err := yaml.Unmarshal([]byte(result.Stdout), obj); err != nil {
+		return result, fmt.Errorf("error unmarshalling kubectl YAML output into %T: %v", obj, err)
+	}
+	return result, nil
+}
+
+// parseWarnings extracts the warnings kubectl printed to stderr, one entry
+// per "Warning:" line with the prefix stripped.
+func parseWarnings(stderr string) []string {
+	var warnings []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if strings.HasPrefix(line, "Warning:") {
+			warnings = append(warnings, strings.TrimSpace(strings.TrimPrefix(line, "Warning:")))
+		}
+	}
+	return warnings
+}
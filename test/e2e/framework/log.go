@@ -53,6 +53,10 @@ func FailfWithOffset(offset int, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 	skip := offset + 1
 	log("FAIL", "%s\n\nFull Stack Trace\n%s", msg, PrunedStack(skip))
+	if currentSpecQuarantined() {
+		log("INFO", "Failure of quarantined spec reported as skip")
+		ginkgo.Skip("[quarantined] "+msg, skip)
+	}
 	e2eginkgowrapper.Fail(nowStamp()+": "+msg, skip)
 }
 
@@ -64,6 +68,10 @@ func Fail(msg string, callerSkip ...int) {
 		skip += callerSkip[0]
 	}
 	log("FAIL", "%s\n\nFull Stack Trace\n%s", msg, PrunedStack(skip))
+	if currentSpecQuarantined() {
+		log("INFO", "Failure of quarantined spec reported as skip")
+		ginkgo.Skip("[quarantined] "+msg, skip)
+	}
 	e2eginkgowrapper.Fail(nowStamp()+": "+msg, skip)
 }
 
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package matchers provides gomega matchers for Kubernetes API types used
+// throughout the e2e tests.
+package matchers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// HaveEquivalentResources succeeds if the actual value is a
+// v1.ResourceRequirements whose requests and limits are semantically equal
+// to the expected ones, comparing quantities by value so "500m" matches
+// "0.5". The failure message lists each differing resource with both
+// quantities.
+func HaveEquivalentResources(expected v1.ResourceRequirements) types.GomegaMatcher {
+	return &equivalentResourcesMatcher{expected: expected}
+}
+
+// HaveEquivalentResourceList succeeds if the actual value is a
+// v1.ResourceList semantically equal to the expected one.
+func HaveEquivalentResourceList(expected v1.ResourceList) types.GomegaMatcher {
+	return &equivalentResourceListMatcher{expected: expected}
+}
+
+// HaveResource succeeds if the actual value is a v1.ResourceList that
+// contains the named resource with a quantity equal in value to the given
+// one, e.g. HaveResource("cpu", "500m").
+func HaveResource(name v1.ResourceName, quantity string) types.GomegaMatcher {
+	return &haveResourceMatcher{name: name, quantity: resource.MustParse(quantity)}
+}
+
+// diffResourceLists returns one line per resource whose quantity differs
+// between expected and actual, in a stable order.
+func diffResourceLists(expected, actual v1.ResourceList) []string {
+	names := map[v1.ResourceName]bool{}
+	for k := range expected {
+		names[k] = true
+	}
+	for k := range actual {
+		names[k] = true
+	}
+	var diffs []string
+	for name := range names {
+		expectedQuantity, expectedFound := expected[name]
+		actualQuantity, actualFound := actual[name]
+		switch {
+		case !expectedFound:
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected, got %s", name, actualQuantity.String()))
+		case !actualFound:
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s, missing", name, expectedQuantity.String()))
+		case expectedQuantity.Cmp(actualQuantity) != 0:
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s, got %s", name, expectedQuantity.String(), actualQuantity.String()))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+type equivalentResourcesMatcher struct {
+	expected v1.ResourceRequirements
+	diffs    []string
+}
+
+func (m *equivalentResourcesMatcher) Match(actual interface{}) (bool, error) {
+	requirements, ok := actual.(v1.ResourceRequirements)
+	if !ok {
+		return false, fmt.Errorf("HaveEquivalentResources expects a v1.ResourceRequirements, got %T", actual)
+	}
+	m.diffs = nil
+	for _, diff := range diffResourceLists(m.expected.Requests, requirements.Requests) {
+		m.diffs = append(m.diffs, "requests."+diff)
+	}
+	for _, diff := range diffResourceLists(m.expected.Limits, requirements.Limits) {
+		m.diffs = append(m.diffs, "limits."+diff)
+	}
+	return len(m.diffs) == 0, nil
+}
+
+func (m *equivalentResourcesMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected resource requirements to be equivalent to\n\t%+v\nbut they differ:\n\t%s", m.expected, strings.Join(m.diffs, "\n\t"))
+}
+
+func (m *equivalentResourcesMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected resource requirements not to be equivalent to\n\t%+v", m.expected)
+}
+
+type equivalentResourceListMatcher struct {
+	expected v1.ResourceList
+	diffs    []string
+}
+
+func (m *equivalentResourceListMatcher) Match(actual interface{}) (bool, error) {
+	list, ok := actual.(v1.ResourceList)
+	if !ok {
+		return false, fmt.Errorf("HaveEquivalentResourceList expects a v1.ResourceList, got %T", actual)
+	}
+	m.diffs = diffResourceLists(m.expected, list)
+	return len(m.diffs) == 0, nil
+}
+
+func (m *equivalentResourceListMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected resource list to be equivalent to\n\t%v\nbut it differs:\n\t%s", m.expected, strings.Join(m.diffs, "\n\t"))
+}
+
+func (m *equivalentResourceListMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected resource list not to be equivalent to\n\t%v", m.expected)
+}
+
+type haveResourceMatcher struct {
+	name     v1.ResourceName
+	quantity resource.Quantity
+}
+
+func (m *haveResourceMatcher) Match(actual interface{}) (bool, error) {
+	list, ok := actual.(v1.ResourceList)
+	if !ok {
+		return false, fmt.Errorf("HaveResource expects a v1.ResourceList, got %T", actual)
+	}
+	quantity, found := list[m.name]
+	return found && m.quantity.Cmp(quantity) == 0, nil
+}
+
+func (m *haveResourceMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected resource list\n\t%v\nto contain %s with quantity %s", actual, m.name, m.quantity.String())
+}
+
+func (m *haveResourceMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected resource list\n\t%v\nnot to contain %s with quantity %s", actual, m.name, m.quantity.String())
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchers
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func resourceList(cpu, memory string) v1.ResourceList {
+	res := v1.ResourceList{}
+	if cpu != "" {
+		res[v1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		res[v1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return res
+}
+
+func TestHaveEquivalentResourceList(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected v1.ResourceList
+		actual   v1.ResourceList
+		match    bool
+	}{
+		{
+			name:     "equal lists match",
+			expected: resourceList("500m", "100Mi"),
+			actual:   resourceList("500m", "100Mi"),
+			match:    true,
+		},
+		{
+			name:     "semantically equal quantities match",
+			expected: resourceList("500m", ""),
+			actual:   resourceList("0.5", ""),
+			match:    true,
+		},
+		{
+			name:     "different quantity does not match",
+			expected: resourceList("500m", "100Mi"),
+			actual:   resourceList("500m", "200Mi"),
+			match:    false,
+		},
+		{
+			name:     "missing resource does not match",
+			expected: resourceList("500m", "100Mi"),
+			actual:   resourceList("500m", ""),
+			match:    false,
+		},
+		{
+			name:     "extra resource does not match",
+			expected: resourceList("500m", ""),
+			actual:   resourceList("500m", "100Mi"),
+			match:    false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher := HaveEquivalentResourceList(test.expected)
+			match, err := matcher.Match(test.actual)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if match != test.match {
+				t.Errorf("expected match=%t, got %t", test.match, match)
+			}
+		})
+	}
+}
+
+func TestHaveEquivalentResourcesFailureMessage(t *testing.T) {
+	expected := v1.ResourceRequirements{
+		Requests: resourceList("500m", "100Mi"),
+		Limits:   resourceList("1", "200Mi"),
+	}
+	actual := v1.ResourceRequirements{
+		Requests: resourceList("250m", "100Mi"),
+		Limits:   resourceList("1", ""),
+	}
+	matcher := HaveEquivalentResources(expected)
+	match, err := matcher.Match(actual)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if match {
+		t.Fatal("expected requirements not to match")
+	}
+	message := matcher.FailureMessage(actual)
+	for _, want := range []string{"requests.cpu: expected 500m, got 250m", "limits.memory: expected 200Mi, missing"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("expected failure message to contain %q, got:\n%s", want, message)
+		}
+	}
+}
+
+func TestHaveResource(t *testing.T) {
+	list := resourceList("500m", "100Mi")
+	for _, test := range []struct {
+		name     v1.ResourceName
+		quantity string
+		match    bool
+	}{
+		{v1.ResourceCPU, "500m", true},
+		{v1.ResourceCPU, "0.5", true},
+		{v1.ResourceCPU, "250m", false},
+		{v1.ResourceStorage, "1Gi", false},
+	} {
+		match, err := HaveResource(test.name, test.quantity).Match(list)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if match != test.match {
+			t.Errorf("HaveResource(%s, %s): expected match=%t, got %t", test.name, test.quantity, test.match, match)
+		}
+	}
+}
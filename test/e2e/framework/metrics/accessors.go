@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"k8s.io/component-base/metrics/testutil"
+)
+
+// sumSamples adds up all samples of the named metric whose labels match the
+// given label values, so counters split over label dimensions can be read as
+// one number.
+func sumSamples(m testutil.Metrics, name string, matchLabels map[string]string) (float64, error) {
+	samples, ok := m[name]
+	if !ok {
+		return 0, fmt.Errorf("metric %q not found", name)
+	}
+	sum := 0.0
+	for _, sample := range samples {
+		matches := true
+		for label, value := range matchLabels {
+			if string(sample.Metric[model.LabelName(label)]) != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			sum += float64(sample.Value)
+		}
+	}
+	return sum, nil
+}
+
+// histogramAverage returns the mean observation of the named histogram
+// metric, computed from its _sum and _count series.
+func histogramAverage(m testutil.Metrics, name string) (time.Duration, error) {
+	sum, err := sumSamples(m, name+"_sum", nil)
+	if err != nil {
+		return 0, err
+	}
+	count, err := sumSamples(m, name+"_count", nil)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("metric %q has no observations", name)
+	}
+	return time.Duration(sum / count * float64(time.Second)), nil
+}
+
+// ScheduleAttempts returns the number of schedule attempts with the given
+// result, one of "scheduled", "unschedulable" or "error".
+func (m SchedulerMetrics) ScheduleAttempts(result string) (int, error) {
+	sum, err := sumSamples(testutil.Metrics(m), "scheduler_schedule_attempts_total", map[string]string{"result": result})
+	if err != nil {
+		return 0, err
+	}
+	return int(sum), nil
+}
+
+// SchedulingLatency returns the average end-to-end scheduling latency the
+// scheduler observed, a common signal in scheduling performance assertions.
+func (m SchedulerMetrics) SchedulingLatency() (time.Duration, error) {
+	return histogramAverage(testutil.Metrics(m), "scheduler_e2e_scheduling_duration_seconds")
+}
+
+// WorkqueueDepth returns the current depth of the named controller
+// workqueue, which indicates whether a controller keeps up with its events.
+func (m ControllerManagerMetrics) WorkqueueDepth(queue string) (int, error) {
+	sum, err := sumSamples(testutil.Metrics(m), "workqueue_depth", map[string]string{"name": queue})
+	if err != nil {
+		return 0, err
+	}
+	return int(sum), nil
+}
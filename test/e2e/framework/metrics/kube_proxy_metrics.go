@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"k8s.io/component-base/metrics/testutil"
+)
+
+// KubeProxyMetrics is metrics for kube-proxy
+type KubeProxyMetrics testutil.Metrics
+
+// Equal returns true if all metrics are the same as the arguments.
+func (m *KubeProxyMetrics) Equal(o KubeProxyMetrics) bool {
+	return (*testutil.Metrics)(m).Equal(testutil.Metrics(o))
+}
+
+func newKubeProxyMetrics() KubeProxyMetrics {
+	result := testutil.NewMetrics()
+	return KubeProxyMetrics(result)
+}
+
+func parseKubeProxyMetrics(data string) (KubeProxyMetrics, error) {
+	result := newKubeProxyMetrics()
+	if err := testutil.ParseMetrics(data, (*testutil.Metrics)(&result)); err != nil {
+		return KubeProxyMetrics{}, err
+	}
+	return result, nil
+}
+
+// SyncProxyRulesLatency returns the average duration of one proxy rules sync
+// iteration, a common signal in dataplane performance assertions.
+func (m KubeProxyMetrics) SyncProxyRulesLatency() (time.Duration, error) {
+	return histogramAverage(testutil.Metrics(m), "kubeproxy_sync_proxy_rules_duration_seconds")
+}
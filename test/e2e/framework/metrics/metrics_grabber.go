@@ -19,13 +19,19 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"sync"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	"k8s.io/kubernetes/test/e2e/system"
 
@@ -41,6 +47,10 @@ const (
 	// May be overridden by a flag at startup.
 	// Deprecated: use the secure KubeControllerManagerPort instead.
 	insecureKubeControllerManagerPort = 10252
+	// kubeProxyMetricsPort is the default port for the kube-proxy metrics
+	// server, which binds to localhost and is therefore only reachable
+	// through a port-forward.
+	kubeProxyMetricsPort = 10249
 )
 
 // Collection is metrics collection of components
@@ -56,6 +66,7 @@ type Collection struct {
 type Grabber struct {
 	client                            clientset.Interface
 	externalClient                    clientset.Interface
+	config                            *restclient.Config
 	grabFromAPIServer                 bool
 	grabFromControllerManager         bool
 	grabFromKubelets                  bool
@@ -63,11 +74,16 @@ type Grabber struct {
 	grabFromClusterAutoscaler         bool
 	masterName                        string
 	registeredMaster                  bool
+	schedulerPodName                  string
+	controllerManagerPodName          string
 	waitForControllerManagerReadyOnce sync.Once
 }
 
-// NewMetricsGrabber returns new metrics which are initialized.
-func NewMetricsGrabber(c clientset.Interface, ec clientset.Interface, kubelets bool, scheduler bool, controllers bool, apiServer bool, clusterAutoscaler bool) (*Grabber, error) {
+// NewMetricsGrabber returns new metrics which are initialized. The rest
+// config may be nil; it is only needed for the port-forward fallback that
+// kicks in when the control plane runs as pods but no master node is
+// registered, as on managed clouds, and for grabbing kube-proxy metrics.
+func NewMetricsGrabber(c clientset.Interface, ec clientset.Interface, config *restclient.Config, kubelets bool, scheduler bool, controllers bool, apiServer bool, clusterAutoscaler bool) (*Grabber, error) {
 	registeredMaster := false
 	masterName := ""
 	nodeList, err := c.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
@@ -84,20 +100,34 @@ func NewMetricsGrabber(c clientset.Interface, ec clientset.Interface, kubelets b
 			break
 		}
 	}
+	schedulerPodName := ""
+	controllerManagerPodName := ""
 	if !registeredMaster {
-		scheduler = false
-		controllers = false
+		// Without a registered master the pod proxy path is unusable, but
+		// the components may still run as pods that a port-forward can
+		// reach.
+		if config != nil {
+			schedulerPodName, _ = componentPodName(c, "kube-scheduler")
+			controllerManagerPodName, _ = componentPodName(c, "kube-controller-manager")
+		}
+		if scheduler && schedulerPodName == "" {
+			scheduler = false
+			klog.Warningf("Master node is not registered and no kube-scheduler pod was found. Grabbing metrics from Scheduler is disabled.")
+		}
+		if controllers && controllerManagerPodName == "" {
+			controllers = false
+			klog.Warningf("Master node is not registered and no kube-controller-manager pod was found. Grabbing metrics from ControllerManager is disabled.")
+		}
 		clusterAutoscaler = ec != nil
-		if clusterAutoscaler {
-			klog.Warningf("Master node is not registered. Grabbing metrics from Scheduler, ControllerManager is disabled.")
-		} else {
-			klog.Warningf("Master node is not registered. Grabbing metrics from Scheduler, ControllerManager and ClusterAutoscaler is disabled.")
+		if !clusterAutoscaler {
+			klog.Warningf("Master node is not registered. Grabbing metrics from ClusterAutoscaler is disabled.")
 		}
 	}
 
 	return &Grabber{
 		client:                    c,
 		externalClient:            ec,
+		config:                    config,
 		grabFromAPIServer:         apiServer,
 		grabFromControllerManager: controllers,
 		grabFromKubelets:          kubelets,
@@ -105,9 +135,29 @@ func NewMetricsGrabber(c clientset.Interface, ec clientset.Interface, kubelets b
 		grabFromClusterAutoscaler: clusterAutoscaler,
 		masterName:                masterName,
 		registeredMaster:          registeredMaster,
+		schedulerPodName:          schedulerPodName,
+		controllerManagerPodName:  controllerManagerPodName,
 	}, nil
 }
 
+// componentPodName returns the name of a running kube-system pod with the
+// given component label, as static control plane pods are labelled by
+// kubeadm and friends.
+func componentPodName(c clientset.Interface, component string) (string, error) {
+	pods, err := c.CoreV1().Pods(metav1.NamespaceSystem).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("component=%v", component),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodRunning {
+			return pod.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no running pod with label component=%v found in namespace %v", component, metav1.NamespaceSystem)
+}
+
 // HasRegisteredMaster returns if metrics grabber was able to find a master node
 func (g *Grabber) HasRegisteredMaster() bool {
 	return g.registeredMaster
@@ -139,10 +189,16 @@ func (g *Grabber) grabFromKubeletInternal(nodeName string, kubeletPort int) (Kub
 
 // GrabFromScheduler returns metrics from scheduler
 func (g *Grabber) GrabFromScheduler() (SchedulerMetrics, error) {
-	if !g.registeredMaster {
-		return SchedulerMetrics{}, fmt.Errorf("Master's Kubelet is not registered. Skipping Scheduler's metrics gathering")
+	var output string
+	var err error
+	switch {
+	case g.registeredMaster:
+		output, err = g.getMetricsFromPod(g.client, fmt.Sprintf("%v-%v", "kube-scheduler", g.masterName), metav1.NamespaceSystem, insecureSchedulerPort)
+	case g.schedulerPodName != "":
+		output, err = g.getMetricsViaPortForward(g.schedulerPodName, metav1.NamespaceSystem, insecureSchedulerPort)
+	default:
+		return SchedulerMetrics{}, fmt.Errorf("Master's Kubelet is not registered and no kube-scheduler pod was found. Skipping Scheduler's metrics gathering")
 	}
-	output, err := g.getMetricsFromPod(g.client, fmt.Sprintf("%v-%v", "kube-scheduler", g.masterName), metav1.NamespaceSystem, insecureSchedulerPort)
 	if err != nil {
 		return SchedulerMetrics{}, err
 	}
@@ -172,12 +228,24 @@ func (g *Grabber) GrabFromClusterAutoscaler() (ClusterAutoscalerMetrics, error)
 
 // GrabFromControllerManager returns metrics from controller manager
 func (g *Grabber) GrabFromControllerManager() (ControllerManagerMetrics, error) {
-	if !g.registeredMaster {
-		return ControllerManagerMetrics{}, fmt.Errorf("Master's Kubelet is not registered. Skipping ControllerManager's metrics gathering")
+	var podName string
+	var fetch func() (string, error)
+	switch {
+	case g.registeredMaster:
+		podName = fmt.Sprintf("%v-%v", "kube-controller-manager", g.masterName)
+		fetch = func() (string, error) {
+			return g.getMetricsFromPod(g.client, podName, metav1.NamespaceSystem, insecureKubeControllerManagerPort)
+		}
+	case g.controllerManagerPodName != "":
+		podName = g.controllerManagerPodName
+		fetch = func() (string, error) {
+			return g.getMetricsViaPortForward(podName, metav1.NamespaceSystem, insecureKubeControllerManagerPort)
+		}
+	default:
+		return ControllerManagerMetrics{}, fmt.Errorf("Master's Kubelet is not registered and no kube-controller-manager pod was found. Skipping ControllerManager's metrics gathering")
 	}
 
 	var err error
-	podName := fmt.Sprintf("%v-%v", "kube-controller-manager", g.masterName)
 	g.waitForControllerManagerReadyOnce.Do(func() {
 		if readyErr := e2epod.WaitForPodsReady(g.client, metav1.NamespaceSystem, podName, 0); readyErr != nil {
 			err = fmt.Errorf("error waiting for controller manager pod to be ready: %w", readyErr)
@@ -186,7 +254,7 @@ func (g *Grabber) GrabFromControllerManager() (ControllerManagerMetrics, error)
 
 		var lastMetricsFetchErr error
 		if metricsWaitErr := wait.PollImmediate(time.Second, time.Minute, func() (bool, error) {
-			_, lastMetricsFetchErr = g.getMetricsFromPod(g.client, podName, metav1.NamespaceSystem, insecureKubeControllerManagerPort)
+			_, lastMetricsFetchErr = fetch()
 			return lastMetricsFetchErr == nil, nil
 		}); metricsWaitErr != nil {
 			err = fmt.Errorf("error waiting for controller manager pod to expose metrics: %v; %v", metricsWaitErr, lastMetricsFetchErr)
@@ -197,13 +265,37 @@ func (g *Grabber) GrabFromControllerManager() (ControllerManagerMetrics, error)
 		return ControllerManagerMetrics{}, err
 	}
 
-	output, err := g.getMetricsFromPod(g.client, podName, metav1.NamespaceSystem, insecureKubeControllerManagerPort)
+	output, err := fetch()
 	if err != nil {
 		return ControllerManagerMetrics{}, err
 	}
 	return parseControllerManagerMetrics(output)
 }
 
+// GrabFromKubeProxy returns metrics from the kube-proxy pod on the given
+// node. The kube-proxy metrics server binds to localhost, so the scrape
+// always goes through a port-forward and needs the grabber's rest config.
+func (g *Grabber) GrabFromKubeProxy(nodeName string) (KubeProxyMetrics, error) {
+	if g.config == nil {
+		return KubeProxyMetrics{}, fmt.Errorf("no rest config available; kube-proxy metrics require a port-forward")
+	}
+	pods, err := g.client.CoreV1().Pods(metav1.NamespaceSystem).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: "k8s-app=kube-proxy",
+		FieldSelector: fields.Set{"spec.nodeName": nodeName}.AsSelector().String(),
+	})
+	if err != nil {
+		return KubeProxyMetrics{}, err
+	}
+	if len(pods.Items) != 1 {
+		return KubeProxyMetrics{}, fmt.Errorf("expected one kube-proxy pod on node %v, got %v", nodeName, len(pods.Items))
+	}
+	output, err := g.getMetricsViaPortForward(pods.Items[0].Name, metav1.NamespaceSystem, kubeProxyMetricsPort)
+	if err != nil {
+		return KubeProxyMetrics{}, err
+	}
+	return parseKubeProxyMetrics(output)
+}
+
 // GrabFromAPIServer returns metrics from API server
 func (g *Grabber) GrabFromAPIServer() (APIServerMetrics, error) {
 	output, err := g.getMetricsFromAPIServer()
@@ -271,6 +363,67 @@ func (g *Grabber) Grab() (Collection, error) {
 	return result, nil
 }
 
+// getMetricsViaPortForward scrapes a pod's /metrics through a port-forward
+// session, which also reaches servers that bind to the pod's localhost. It
+// cannot use the framework's portforward package without an import cycle, so
+// it drives the client-go forwarder directly.
+func (g *Grabber) getMetricsViaPortForward(podName string, namespace string, port int) (string, error) {
+	if g.config == nil {
+		return "", fmt.Errorf("no rest config available for port-forwarding to %v/%v", namespace, podName)
+	}
+	req := g.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(g.config)
+	if err != nil {
+		return "", fmt.Errorf("error creating spdy round tripper: %v", err)
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	fw, err := portforward.New(dialer, []string{fmt.Sprintf("0:%d", port)}, stopCh, readyCh, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating port forwarder: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+	defer close(stopCh)
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return "", fmt.Errorf("port forwarding to %v/%v failed before becoming ready: %v", namespace, podName, err)
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil {
+		return "", fmt.Errorf("error getting forwarded ports: %v", err)
+	}
+	if len(ports) != 1 {
+		return "", fmt.Errorf("expected one forwarded port, got %v", ports)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", ports[0].Local))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metrics endpoint of %v/%v returned %v", namespace, podName, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
 func (g *Grabber) getMetricsFromPod(client clientset.Interface, podName string, namespace string, port int) (string, error) {
 	rawOutput, err := client.CoreV1().RESTClient().Get().
 		Namespace(namespace).
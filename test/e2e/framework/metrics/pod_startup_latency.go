@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodStartupLatencyMeasurer records creation-to-running latencies for the
+// pods of one namespace through a watch, so a batch test can derive latency
+// percentiles without polling and without the sampling error polling brings.
+type PodStartupLatencyMeasurer struct {
+	client        clientset.Interface
+	namespace     string
+	labelSelector string
+
+	stopCh chan struct{}
+
+	lock      sync.Mutex
+	seen      map[string]bool
+	latencies LatencySlice
+}
+
+// NewPodStartupLatencyMeasurer returns a measurer for the pods matching the
+// label selector in the given namespace. Start must be called before the
+// pods are created, so that the watch observes every transition.
+func NewPodStartupLatencyMeasurer(c clientset.Interface, namespace, labelSelector string) *PodStartupLatencyMeasurer {
+	return &PodStartupLatencyMeasurer{
+		client:        c,
+		namespace:     namespace,
+		labelSelector: labelSelector,
+		stopCh:        make(chan struct{}),
+		seen:          map[string]bool{},
+	}
+}
+
+// Start begins watching for pods. Each pod's latency is taken when the watch
+// first delivers it with phase Running, as the time since the pod's creation
+// timestamp.
+func (p *PodStartupLatencyMeasurer) Start() {
+	listWatch := cache.NewFilteredListWatchFromClient(
+		p.client.CoreV1().RESTClient(),
+		"pods",
+		p.namespace,
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = p.labelSelector
+		},
+	)
+	_, controller := cache.NewInformer(listWatch, &v1.Pod{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: p.observe,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			p.observe(newObj)
+		},
+	})
+	go controller.Run(p.stopCh)
+}
+
+// Stop ends the watch and returns the recorded latencies, sorted ascending.
+func (p *PodStartupLatencyMeasurer) Stop() LatencySlice {
+	close(p.stopCh)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	sort.Sort(p.latencies)
+	return p.latencies
+}
+
+// observe records the latency for a pod the watch reported, once per pod and
+// only when the pod has reached phase Running.
+func (p *PodStartupLatencyMeasurer) observe(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok || pod.Status.Phase != v1.PodRunning {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.seen[pod.Name] {
+		return
+	}
+	p.seen[pod.Name] = true
+	p.latencies = append(p.latencies, PodLatencyData{
+		Name:    pod.Name,
+		Node:    pod.Spec.NodeName,
+		Latency: time.Since(pod.CreationTimestamp.Time),
+	})
+}
+
+// ExtractLatencyMetrics returns the 50th, 90th, 99th and 100th percentiles
+// of the given sorted latencies.
+func ExtractLatencyMetrics(latencies LatencySlice) LatencyMetric {
+	length := len(latencies)
+	perc50 := latencies[int(math.Ceil(float64(length*50)/100))-1].Latency
+	perc90 := latencies[int(math.Ceil(float64(length*90)/100))-1].Latency
+	perc99 := latencies[int(math.Ceil(float64(length*99)/100))-1].Latency
+	perc100 := latencies[length-1].Latency
+	return LatencyMetric{Perc50: perc50, Perc90: perc90, Perc99: perc99, Perc100: perc100}
+}
+
+// VerifyLatencyWithinThreshold returns an error if the actual latency
+// percentiles exceed the threshold's, for enforcing startup SLOs.
+func VerifyLatencyWithinThreshold(threshold, actual LatencyMetric, metricName string) error {
+	if actual.Perc50 > threshold.Perc50 {
+		return fmt.Errorf("too high %v latency 50th percentile: %v (threshold %v)", metricName, actual.Perc50, threshold.Perc50)
+	}
+	if actual.Perc90 > threshold.Perc90 {
+		return fmt.Errorf("too high %v latency 90th percentile: %v (threshold %v)", metricName, actual.Perc90, threshold.Perc90)
+	}
+	if actual.Perc99 > threshold.Perc99 {
+		return fmt.Errorf("too high %v latency 99th percentile: %v (threshold %v)", metricName, actual.Perc99, threshold.Perc99)
+	}
+	return nil
+}
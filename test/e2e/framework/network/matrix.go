@@ -0,0 +1,321 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+)
+
+const (
+	// number of exec probes evaluated concurrently when walking the matrix.
+	matrixProbeParallelism = 8
+	// number of attempts per source/destination pair before the pair is
+	// recorded as unreachable. Individual attempts can fail transiently
+	// while kube-proxy or the CNI plugin converges.
+	matrixProbeTries = 3
+	// wait time between attempts of a single matrix probe.
+	matrixProbeRetryDelay = 2 * time.Second
+)
+
+// MatrixEndpoint is a single agnhost netexec pod participating in a
+// connectivity matrix, acting as both a probe source and a probe target.
+type MatrixEndpoint struct {
+	// Pod is the running netexec pod backing this endpoint.
+	Pod *v1.Pod
+	// HostNetwork indicates the pod runs on the node's network namespace.
+	HostNetwork bool
+}
+
+// label returns a short human readable identifier used in matrix output.
+func (e *MatrixEndpoint) label() string {
+	network := "pod"
+	if e.HostNetwork {
+		network = "host"
+	}
+	return fmt.Sprintf("%s(%s,%s)", e.Pod.Name, e.Pod.Spec.NodeName, network)
+}
+
+// ConnectivityMatrix deploys one agnhost netexec pod per schedulable node,
+// optionally a second set on the host network, and evaluates reachability
+// between every pair of endpoints for every requested protocol. It is meant
+// to be shared by the service, network policy and dual-stack suites, which
+// all need the same "can everything talk to everything" precondition or
+// assertion.
+type ConnectivityMatrix struct {
+	// Endpoints are the deployed probe pods, one (or two with host network)
+	// per schedulable node.
+	Endpoints []*MatrixEndpoint
+	// Protocols are the protocols evaluated between every endpoint pair.
+	// Valid values are the netexec dial protocols "http", "udp" and "sctp".
+	Protocols []string
+
+	f         *framework.Framework
+	namespace string
+}
+
+// matrixFailure records a single unreachable source/destination pair.
+type matrixFailure struct {
+	from, to *MatrixEndpoint
+	protocol string
+	reason   string
+}
+
+// MatrixResult holds the outcome of one evaluation of a ConnectivityMatrix.
+type MatrixResult struct {
+	matrix   *ConnectivityMatrix
+	probes   int
+	failures []matrixFailure
+}
+
+// Passed returns true if every probed pair was reachable.
+func (r *MatrixResult) Passed() bool {
+	return len(r.failures) == 0
+}
+
+// String renders the result as one grid per protocol, with probe sources as
+// rows and targets as columns, so a failure report shows at a glance whether
+// a whole node, network or protocol is broken rather than a single pair.
+func (r *MatrixResult) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d/%d probes failed\n", len(r.failures), r.probes)
+	failed := map[string]string{}
+	for _, f := range r.failures {
+		failed[f.protocol+"/"+f.from.label()+"/"+f.to.label()] = f.reason
+	}
+	for _, protocol := range r.matrix.Protocols {
+		fmt.Fprintf(&b, "protocol %s (rows dial columns, '.' reachable, 'X' unreachable):\n", protocol)
+		fmt.Fprintf(&b, "%-40s", "")
+		for i := range r.matrix.Endpoints {
+			fmt.Fprintf(&b, " %3d", i)
+		}
+		fmt.Fprintln(&b)
+		for i, from := range r.matrix.Endpoints {
+			fmt.Fprintf(&b, "%3d %-36s", i, from.label())
+			for _, to := range r.matrix.Endpoints {
+				if _, ok := failed[protocol+"/"+from.label()+"/"+to.label()]; ok {
+					fmt.Fprintf(&b, " %3s", "X")
+				} else {
+					fmt.Fprintf(&b, " %3s", ".")
+				}
+			}
+			fmt.Fprintln(&b)
+		}
+	}
+	for _, f := range r.failures {
+		fmt.Fprintf(&b, "%s: %s -> %s: %s\n", f.protocol, f.from.label(), f.to.label(), f.reason)
+	}
+	return b.String()
+}
+
+// NewConnectivityMatrix deploys the probe pods for a connectivity matrix in
+// the framework's namespace and waits for all of them to become ready. With
+// includeHostNetwork set, every node additionally gets a host network probe
+// pod so node-to-pod and pod-to-node paths are part of the matrix. Protocols
+// must be a subset of "http", "udp" and "sctp"; note that sctp probes may
+// load the sctp kernel module on the nodes, so callers evaluating sctp
+// should be marked accordingly.
+func NewConnectivityMatrix(f *framework.Framework, includeHostNetwork bool, protocols ...string) *ConnectivityMatrix {
+	m := &ConnectivityMatrix{
+		Protocols: protocols,
+		f:         f,
+		namespace: f.Namespace.Name,
+	}
+	ginkgo.By(fmt.Sprintf("Deploying connectivity matrix probe pods in namespace %v", m.namespace))
+
+	framework.ExpectNoError(framework.WaitForAllNodesSchedulable(f.ClientSet, 10*time.Minute))
+	nodeList, err := e2enode.GetBoundedReadySchedulableNodes(f.ClientSet, maxNetProxyPodsCount)
+	framework.ExpectNoError(err)
+
+	var createdPods []*MatrixEndpoint
+	for i, n := range nodeList.Items {
+		hostname := n.Labels["kubernetes.io/hostname"]
+		pod := f.PodClient().Create(matrixPodSpec(fmt.Sprintf("matrix-pod-%d", i), hostname, false, m.sctpEnabled()))
+		createdPods = append(createdPods, &MatrixEndpoint{Pod: pod})
+		if includeHostNetwork {
+			pod := f.PodClient().Create(matrixPodSpec(fmt.Sprintf("matrix-host-%d", i), hostname, true, m.sctpEnabled()))
+			createdPods = append(createdPods, &MatrixEndpoint{Pod: pod, HostNetwork: true})
+		}
+	}
+
+	for _, e := range createdPods {
+		framework.ExpectNoError(e2epod.WaitTimeoutForPodReadyInNamespace(f.ClientSet, e.Pod.Name, m.namespace, framework.PodStartTimeout))
+		pod, err := f.PodClient().Get(context.TODO(), e.Pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		e.Pod = pod
+	}
+	m.Endpoints = createdPods
+	return m
+}
+
+func (m *ConnectivityMatrix) sctpEnabled() bool {
+	for _, p := range m.Protocols {
+		if p == "sctp" {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate probes every source/destination pair for every protocol of the
+// matrix in parallel and returns the collected result without failing the
+// test, so callers can also assert expected unreachability.
+func (m *ConnectivityMatrix) Evaluate() *MatrixResult {
+	type probe struct {
+		from, to *MatrixEndpoint
+		protocol string
+	}
+	var probes []probe
+	for _, protocol := range m.Protocols {
+		for _, from := range m.Endpoints {
+			for _, to := range m.Endpoints {
+				probes = append(probes, probe{from: from, to: to, protocol: protocol})
+			}
+		}
+	}
+	ginkgo.By(fmt.Sprintf("Evaluating a %dx%d connectivity matrix over %v (%d probes)", len(m.Endpoints), len(m.Endpoints), m.Protocols, len(probes)))
+
+	result := &MatrixResult{matrix: m, probes: len(probes)}
+	var lock sync.Mutex
+	workqueue.ParallelizeUntil(context.TODO(), matrixProbeParallelism, len(probes), func(i int) {
+		p := probes[i]
+		if reason := m.probe(p.from, p.to, p.protocol); reason != "" {
+			lock.Lock()
+			defer lock.Unlock()
+			result.failures = append(result.failures, matrixFailure{from: p.from, to: p.to, protocol: p.protocol, reason: reason})
+		}
+	})
+	return result
+}
+
+// ExpectFullConnectivity evaluates the matrix and fails the test with the
+// rendered grid if any pair was unreachable.
+func (m *ConnectivityMatrix) ExpectFullConnectivity() {
+	result := m.Evaluate()
+	if !result.Passed() {
+		framework.Failf("Connectivity matrix is not fully connected:\n%s", result)
+	}
+	framework.Logf("Connectivity matrix is fully connected, %d probes passed", result.probes)
+}
+
+// probe dials from one endpoint to another via the source pod's netexec
+// /dial handler and returns an empty string on success or a reason string
+// after all attempts failed.
+func (m *ConnectivityMatrix) probe(from, to *MatrixEndpoint, protocol string) string {
+	targetPort := EndpointHTTPPort
+	switch protocol {
+	case "udp":
+		targetPort = EndpointUDPPort
+	case "sctp":
+		targetPort = EndpointSCTPPort
+	}
+	fromIPPort := net.JoinHostPort(from.Pod.Status.PodIP, strconv.Itoa(EndpointHTTPPort))
+	// The -g argument is required to stop curl from interpreting square
+	// brackets around IPv6 addresses as globbing, as in DialFromContainer.
+	cmd := fmt.Sprintf("curl -g -q -s 'http://%s/dial?request=%s&protocol=%s&host=%s&port=%d&tries=1'",
+		fromIPPort, echoHostname, protocol, to.Pod.Status.PodIP, targetPort)
+
+	lastReason := ""
+	for i := 0; i < matrixProbeTries; i++ {
+		if i > 0 {
+			time.Sleep(matrixProbeRetryDelay)
+		}
+		stdout, stderr, err := m.f.ExecShellInPodWithFullOutput(from.Pod.Name, cmd)
+		if err != nil {
+			lastReason = fmt.Sprintf("failed to execute %q: %v, stdout: %q, stderr: %q", cmd, err, stdout, stderr)
+			continue
+		}
+		var output map[string][]string
+		if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+			lastReason = fmt.Sprintf("failed to unmarshal dial response %q: %v", stdout, err)
+			continue
+		}
+		for _, response := range output["responses"] {
+			if strings.TrimSpace(response) != "" {
+				return ""
+			}
+		}
+		lastReason = fmt.Sprintf("no response from %s:%d", to.Pod.Status.PodIP, targetPort)
+	}
+	return lastReason
+}
+
+// matrixPodSpec returns a netexec pod pinned to the given node, mirroring
+// createNetShellPodSpec but with an optional host network placement.
+func matrixPodSpec(podName, hostname string, hostNetwork, sctpEnabled bool) *v1.Pod {
+	pod := &v1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: podName,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:            "webserver",
+					Image:           NetexecImageName,
+					ImagePullPolicy: v1.PullIfNotPresent,
+					Args: []string{
+						"netexec",
+						fmt.Sprintf("--http-port=%d", EndpointHTTPPort),
+						fmt.Sprintf("--udp-port=%d", EndpointUDPPort),
+					},
+					Ports: []v1.ContainerPort{
+						{
+							Name:          "http",
+							ContainerPort: EndpointHTTPPort,
+						},
+						{
+							Name:          "udp",
+							ContainerPort: EndpointUDPPort,
+							Protocol:      v1.ProtocolUDP,
+						},
+					},
+				},
+			},
+			HostNetwork: hostNetwork,
+			NodeSelector: map[string]string{
+				"kubernetes.io/hostname": hostname,
+			},
+		},
+	}
+	if sctpEnabled {
+		pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--sctp-port=%d", EndpointSCTPPort))
+		pod.Spec.Containers[0].Ports = append(pod.Spec.Containers[0].Ports, v1.ContainerPort{
+			Name:          "sctp",
+			ContainerPort: EndpointSCTPPort,
+			Protocol:      v1.ProtocolSCTP,
+		})
+	}
+	return pod
+}
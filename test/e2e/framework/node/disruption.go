@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
+	e2essh "k8s.io/kubernetes/test/e2e/framework/ssh"
+)
+
+const (
+	// nodeReadyTimeout is how long RestartKubelet and RebootNode wait for the
+	// node to report ready again after the disruption.
+	nodeReadyTimeout = 5 * time.Minute
+	// rebootNotReadyTimeout is how long RebootNode waits for the node to go
+	// not-ready after the reboot command was issued.
+	rebootNotReadyTimeout = 2 * time.Minute
+)
+
+// sshAddress returns the ssh address of the named node, preferring the
+// external IP and falling back to the internal one.
+func sshAddress(c clientset.Interface, name string) (string, error) {
+	node, err := c.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if host, err := GetExternalIP(node); err == nil {
+		return host, nil
+	}
+	return GetInternalIP(node)
+}
+
+// kubeletServiceCommand builds the command to run the given operation on the
+// kubelet service, using systemctl where present and falling back to service,
+// with sudo prepended where present.
+func kubeletServiceCommand(host, provider, op string) (string, error) {
+	command := fmt.Sprintf("service kubelet %s", op)
+	result, err := e2essh.SSH("systemctl --version", host, provider)
+	if err != nil {
+		return "", fmt.Errorf("SSH to %q errored: %v", host, err)
+	}
+	if !strings.Contains(result.Stderr, "command not found") {
+		command = fmt.Sprintf("systemctl %s kubelet", op)
+	}
+	result, err = e2essh.SSH("sudo --version", host, provider)
+	if err != nil {
+		return "", fmt.Errorf("SSH to %q errored: %v", host, err)
+	}
+	if !strings.Contains(result.Stderr, "command not found") {
+		command = fmt.Sprintf("sudo %s", command)
+	}
+	return command, nil
+}
+
+// RestartKubelet restarts the kubelet on the given node over ssh and waits
+// for the node to report ready again, so tests can verify recovery behavior
+// such as volumes staying mounted across a kubelet restart.
+func RestartKubelet(c clientset.Interface, provider, name string) error {
+	host, err := sshAddress(c, name)
+	if err != nil {
+		return err
+	}
+	command, err := kubeletServiceCommand(host, provider, "restart")
+	if err != nil {
+		return err
+	}
+	e2elog.Logf("Restarting kubelet on node %q, running: %v", name, command)
+	result, err := e2essh.SSH(command, host, provider)
+	if err != nil || result.Code != 0 {
+		e2essh.LogResult(result)
+		return fmt.Errorf("couldn't restart kubelet on node %q: %v", name, err)
+	}
+	return WaitForNodeReady(c, name, nodeReadyTimeout)
+}
+
+// RebootNode reboots the given node over ssh, waits for it to become
+// not-ready and then for it to report ready again.
+func RebootNode(c clientset.Interface, provider, name string) error {
+	host, err := sshAddress(c, name)
+	if err != nil {
+		return err
+	}
+	// Run the reboot in the background and give ssh a moment to return,
+	// otherwise the connection is torn down before the command is issued.
+	command := "nohup sudo reboot >/dev/null 2>&1 </dev/null & sleep 1"
+	e2elog.Logf("Rebooting node %q, running: %v", name, command)
+	result, err := e2essh.SSH(command, host, provider)
+	if err != nil {
+		e2essh.LogResult(result)
+		return fmt.Errorf("couldn't reboot node %q: %v", name, err)
+	}
+	if !WaitForNodeToBeNotReady(c, name, rebootNotReadyTimeout) {
+		return fmt.Errorf("node %q didn't become not-ready after reboot within %v", name, rebootNotReadyTimeout)
+	}
+	return WaitForNodeReady(c, name, nodeReadyTimeout)
+}
+
+// WaitForNodeReady waits for the node to report ready, returning an error on
+// timeout so callers can thread the failure instead of asserting.
+func WaitForNodeReady(c clientset.Interface, name string, timeout time.Duration) error {
+	if !WaitForNodeToBeReady(c, name, timeout) {
+		return fmt.Errorf("node %q didn't become ready within %v", name, timeout)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
+)
+
+const (
+	// labelLockNamespace is where the coordination Leases guarding label
+	// prefixes are kept.
+	labelLockNamespace = metav1.NamespaceDefault
+
+	// labelLockLeaseDurationSeconds is how long a holder may go without
+	// renewing before another process takes the lease over. It is generous
+	// because holders renew only when they mutate labels.
+	labelLockLeaseDurationSeconds = 600
+
+	labelLockRetryPeriod    = 5 * time.Second
+	labelLockAcquireTimeout = 10 * time.Minute
+)
+
+// LabelLock serializes node label mutations under one label prefix across
+// parallel suite processes by holding a coordination.k8s.io Lease, so suites
+// that label nodes do not have to be [Serial] to avoid clobbering each other.
+type LabelLock struct {
+	c         clientset.Interface
+	prefix    string
+	leaseName string
+	holder    string
+}
+
+// AcquireLabelLock blocks until this process holds the Lease guarding the
+// given label prefix, creating the Lease on first use and taking over leases
+// whose holder stopped renewing.
+func AcquireLabelLock(c clientset.Interface, prefix string) (*LabelLock, error) {
+	lock := &LabelLock{
+		c:         c,
+		prefix:    prefix,
+		leaseName: "e2e-node-labels-" + strings.NewReplacer("/", "-", ".", "-").Replace(strings.TrimSuffix(prefix, "/")),
+		holder:    string(uuid.NewUUID()),
+	}
+	if err := wait.PollImmediate(labelLockRetryPeriod, labelLockAcquireTimeout, lock.tryAcquire); err != nil {
+		return nil, fmt.Errorf("could not acquire lease %s for label prefix %s: %v", lock.leaseName, prefix, err)
+	}
+	return lock, nil
+}
+
+func (l *LabelLock) tryAcquire() (bool, error) {
+	leases := l.c.CoordinationV1().Leases(labelLockNamespace)
+	now := metav1.NewMicroTime(time.Now())
+	duration := int32(labelLockLeaseDurationSeconds)
+	lease, err := leases.Get(context.TODO(), l.leaseName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: l.leaseName},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &l.holder,
+				LeaseDurationSeconds: &duration,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(context.TODO(), lease, metav1.CreateOptions{}); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	spec := lease.Spec
+	heldByOther := spec.HolderIdentity != nil && *spec.HolderIdentity != "" && *spec.HolderIdentity != l.holder
+	if heldByOther && spec.RenewTime != nil && spec.LeaseDurationSeconds != nil &&
+		time.Since(spec.RenewTime.Time) < time.Duration(*spec.LeaseDurationSeconds)*time.Second {
+		// Someone else holds the lease and is still renewing it.
+		return false, nil
+	}
+	if heldByOther {
+		e2elog.Logf("Taking over expired lease %s from holder %s", l.leaseName, *spec.HolderIdentity)
+	}
+	lease.Spec.HolderIdentity = &l.holder
+	lease.Spec.LeaseDurationSeconds = &duration
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	if _, err := leases.Update(context.TODO(), lease, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Release gives the Lease up so other processes can mutate labels under the
+// prefix. Releasing a lease this process no longer holds is a no-op.
+func (l *LabelLock) Release() error {
+	leases := l.c.CoordinationV1().Leases(labelLockNamespace)
+	lease, err := leases.Get(context.TODO(), l.leaseName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.holder {
+		return nil
+	}
+	empty := ""
+	lease.Spec.HolderIdentity = &empty
+	_, err = leases.Update(context.TODO(), lease, metav1.UpdateOptions{})
+	return err
+}
+
+// separateScopedLabels splits a node's labels into those under the lock's
+// prefix and the rest.
+func (l *LabelLock) separateScopedLabels(labels map[string]string) (map[string]string, map[string]string) {
+	scoped := map[string]string{}
+	others := map[string]string{}
+	for k, v := range labels {
+		if strings.HasPrefix(k, l.prefix) {
+			scoped[k] = v
+		} else {
+			others[k] = v
+		}
+	}
+	return scoped, others
+}
+
+// SetLabels replaces the labels under the lock's prefix on the named node
+// with the given set, retrying on update conflicts. All keys must carry the
+// lock's prefix.
+func (l *LabelLock) SetLabels(nodeName string, labels map[string]string) (*v1.Node, error) {
+	for k := range labels {
+		if !strings.HasPrefix(k, l.prefix) {
+			return nil, fmt.Errorf("label %s is outside the locked prefix %s", k, l.prefix)
+		}
+	}
+	nodeClient := l.c.CoreV1().Nodes()
+	var newNode *v1.Node
+	err := wait.PollImmediate(labelLockRetryPeriod, labelLockAcquireTimeout, func() (bool, error) {
+		node, err := nodeClient.Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		scoped, others := l.separateScopedLabels(node.Labels)
+		if reflect.DeepEqual(scoped, labels) {
+			newNode = node
+			return true, nil
+		}
+		node.Labels = others
+		for k, v := range labels {
+			node.Labels[k] = v
+		}
+		newNode, err = nodeClient.Update(context.TODO(), node, metav1.UpdateOptions{})
+		if err == nil {
+			return true, nil
+		}
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, err
+	})
+	return newNode, err
+}
+
+// ClearLabels removes every label under the lock's prefix from all nodes.
+func (l *LabelLock) ClearLabels() error {
+	nodeList, err := l.c.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range nodeList.Items {
+		if _, err := l.SetLabels(nodeList.Items[i].Name, map[string]string{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
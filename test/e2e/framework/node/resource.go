@@ -341,10 +341,64 @@ func GetBoundedReadySchedulableNodes(c clientset.Interface, maxNodes int) (nodes
 // GetRandomReadySchedulableNode gets a single randomly-selected node which is available for
 // running pods on. If there are no available nodes it will return an error.
 func GetRandomReadySchedulableNode(c clientset.Interface) (*v1.Node, error) {
+	return PickOne(c)
+}
+
+// Constraint is a named predicate over nodes for PickOne. The name shows up
+// in the error returned when no node matches, so it can be used verbatim in
+// an informative skip.
+type Constraint struct {
+	// Name describes the constraint, e.g. "has label foo=bar".
+	Name string
+	// Matches reports whether the node satisfies the constraint.
+	Matches func(node *v1.Node) bool
+}
+
+// HasLabel is a constraint satisfied by nodes carrying the given label.
+func HasLabel(key, value string) Constraint {
+	return Constraint{
+		Name: fmt.Sprintf("has label %s=%s", key, value),
+		Matches: func(node *v1.Node) bool {
+			return node.Labels[key] == value
+		},
+	}
+}
+
+// IsOS is a constraint satisfied by nodes running the given operating
+// system, e.g. "linux" or "windows".
+func IsOS(os string) Constraint {
+	return Constraint{
+		Name: fmt.Sprintf("runs %s", os),
+		Matches: func(node *v1.Node) bool {
+			return node.Labels[v1.LabelOSStable] == os
+		},
+	}
+}
+
+// PickOne returns a single randomly-selected node which is ready, schedulable
+// and satisfies all the given constraints. When no node matches, the error
+// names the constraints so callers can turn it into an informative skip.
+func PickOne(c clientset.Interface, constraints ...Constraint) (*v1.Node, error) {
 	nodes, err := GetReadySchedulableNodes(c)
 	if err != nil {
 		return nil, err
 	}
+	total := len(nodes.Items)
+	Filter(nodes, func(node v1.Node) bool {
+		for _, constraint := range constraints {
+			if !constraint.Matches(&node) {
+				return false
+			}
+		}
+		return true
+	})
+	if len(nodes.Items) == 0 {
+		names := make([]string, 0, len(constraints))
+		for _, constraint := range constraints {
+			names = append(names, constraint.Name)
+		}
+		return nil, fmt.Errorf("none of the %d ready schedulable nodes matches: %s", total, strings.Join(names, ", "))
+	}
 	return &nodes.Items[rand.Intn(len(nodes.Items))], nil
 }
 
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
+	uexec "k8s.io/utils/exec"
+)
+
+// ExecResult holds the outcome of a command executed in a container.
+type ExecResult struct {
+	Stdout string
+	Stderr string
+	// Code is the command's exit code, 0 on success. It is only meaningful
+	// when the accompanying error is nil or a command failure; transport
+	// errors leave it at -1.
+	Code int
+}
+
+// ExecWithResult runs a command in the given container and returns its
+// stdout, stderr and exit code as structured data. Transient streaming
+// errors (for example a dropped SPDY connection) are retried up to retries
+// times; a command that runs and exits nonzero is not retried, its exit
+// code is recorded in the result instead.
+func ExecWithResult(config *restclient.Config, c clientset.Interface, namespace, podName, containerName string, retries int, command ...string) (ExecResult, error) {
+	result := ExecResult{Code: -1}
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			e2elog.Logf("Retrying exec in %s/%s (container %s) after transient error: %v", namespace, podName, containerName, lastErr)
+			time.Sleep(poll)
+		}
+		var stdout, stderr bytes.Buffer
+		err := execCommand(config, c, namespace, podName, containerName, command, &stdout, &stderr)
+		result.Stdout = stdout.String()
+		result.Stderr = stderr.String()
+		if err == nil {
+			result.Code = 0
+			return result, nil
+		}
+		if exitErr, ok := err.(uexec.ExitError); ok {
+			// The command ran and failed; report the exit code rather than
+			// retrying.
+			result.Code = exitErr.ExitStatus()
+			return result, nil
+		}
+		lastErr = err
+	}
+	return result, fmt.Errorf("failed to exec in pod %s/%s after %d attempts: %v", namespace, podName, retries+1, lastErr)
+}
+
+// execCommand performs a single streaming exec into the container.
+func execCommand(config *restclient.Config, c clientset.Interface, namespace, podName, containerName string, command []string, stdout, stderr *bytes.Buffer) error {
+	req := c.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdin:     false,
+			Stdout:    true,
+			Stderr:    true,
+			TTY:       false,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return executor.Stream(remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	netutils "k8s.io/utils/net"
+)
+
+// PodIPByFamily returns the first address of the given IP family reported in
+// the pod's status.podIPs, or an empty string if the pod has none.
+func PodIPByFamily(pod *v1.Pod, family v1.IPFamily) string {
+	for _, podIP := range pod.Status.PodIPs {
+		if (family == v1.IPv6Protocol) == netutils.IsIPv6String(podIP.IP) {
+			return podIP.IP
+		}
+	}
+	return ""
+}
+
+// VerifyPodIPFamilies returns an error unless the pod reports at least one
+// address of every requested IP family in status.podIPs.
+func VerifyPodIPFamilies(pod *v1.Pod, families []v1.IPFamily) error {
+	for _, family := range families {
+		if PodIPByFamily(pod, family) == "" {
+			return fmt.Errorf("pod %s/%s has no %s address in status.podIPs: %v", pod.Namespace, pod.Name, family, pod.Status.PodIPs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultNodeOS is the operating system pods built by the test helpers are
+// expected to run on. It is "linux" unless the suite was started with
+// --node-os-distro=windows, in which case AfterReadingAllFlags switches it
+// to "windows" so helpers target Windows nodes and suites can run on
+// Windows-only clusters.
+var DefaultNodeOS = "linux"
+
+// SetNodeOSSelector constrains the pod to nodes running the given operating
+// system through the kubernetes.io/os label. The pod spec has no OS field in
+// this API version, so a scheduling constraint is the only way to express
+// the requirement.
+func SetNodeOSSelector(podSpec *v1.PodSpec, os string) {
+	if podSpec.NodeSelector == nil {
+		podSpec.NodeSelector = map[string]string{}
+	}
+	podSpec.NodeSelector[v1.LabelOSStable] = os
+}
+
+// SetDefaultNodeOSSelector constrains the pod to nodes running DefaultNodeOS.
+// Linux is left unconstrained to keep pod specs unchanged on the common
+// homogeneous Linux clusters.
+func SetDefaultNodeOSSelector(podSpec *v1.PodSpec) {
+	if DefaultNodeOS == "linux" {
+		return
+	}
+	SetNodeOSSelector(podSpec, DefaultNodeOS)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// CreatePodsInParallel creates the given pods with a bounded worker pool and
+// waits for all of them to be running. A single informer watches the whole
+// namespace instead of polling each pod individually, which keeps the apiserver
+// load constant regardless of the number of pods. It returns the created pods
+// in the order they were passed in, or an aggregate of every creation error.
+func CreatePodsInParallel(c clientset.Interface, namespace string, pods []*v1.Pod, workers int, timeout time.Duration) ([]*v1.Pod, error) {
+	var lock sync.Mutex
+	var errs []error
+	created := make([]*v1.Pod, len(pods))
+	workqueue.ParallelizeUntil(context.TODO(), workers, len(pods), func(i int) {
+		pod, err := c.CoreV1().Pods(namespace).Create(context.TODO(), pods[i], metav1.CreateOptions{})
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create pod %s: %v", pods[i].Name, err))
+			return
+		}
+		created[i] = pod
+	})
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	names := sets.NewString()
+	for _, pod := range created {
+		names.Insert(pod.Name)
+	}
+	if err := waitForPodNamesWithInformer(c, namespace, names, timeout, false); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeletePodsInParallel deletes the named pods with a bounded worker pool and
+// waits for all of them to be gone, sharing a single informer across all
+// deletions. Pods that do not exist are tolerated, so the helper can be used
+// for cleanup paths that race with controllers.
+func DeletePodsInParallel(c clientset.Interface, namespace string, podNames []string, workers int, timeout time.Duration) error {
+	var lock sync.Mutex
+	var errs []error
+	workqueue.ParallelizeUntil(context.TODO(), workers, len(podNames), func(i int) {
+		err := c.CoreV1().Pods(namespace).Delete(context.TODO(), podNames[i], metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			lock.Lock()
+			defer lock.Unlock()
+			errs = append(errs, fmt.Errorf("failed to delete pod %s: %v", podNames[i], err))
+		}
+	})
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	return waitForPodNamesWithInformer(c, namespace, sets.NewString(podNames...), timeout, true)
+}
+
+// waitForPodNamesWithInformer runs one informer over the namespace's pods and
+// blocks until every named pod is running (or deleted, with waitForDeletion
+// set), or the timeout expires. The timeout error lists the pods that were
+// still pending.
+func waitForPodNamesWithInformer(c clientset.Interface, namespace string, names sets.String, timeout time.Duration, waitForDeletion bool) error {
+	var lock sync.Mutex
+	remaining := sets.NewString(names.List()...)
+	done := make(chan struct{})
+	var closeOnce sync.Once
+
+	// markDone removes a pod from the pending set and signals completion once
+	// the set drains. Callers must hold lock.
+	markDone := func(name string) {
+		remaining.Delete(name)
+		if remaining.Len() == 0 {
+			closeOnce.Do(func() { close(done) })
+		}
+	}
+
+	podFromObj := func(obj interface{}) *v1.Pod {
+		if pod, ok := obj.(*v1.Pod); ok {
+			return pod
+		}
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			if pod, ok := tombstone.Obj.(*v1.Pod); ok {
+				return pod
+			}
+		}
+		return nil
+	}
+	onRunning := func(obj interface{}) {
+		pod := podFromObj(obj)
+		if pod == nil || pod.Status.Phase != v1.PodRunning {
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if remaining.Has(pod.Name) {
+			markDone(pod.Name)
+		}
+	}
+	handlers := cache.ResourceEventHandlerFuncs{}
+	if waitForDeletion {
+		handlers.DeleteFunc = func(obj interface{}) {
+			pod := podFromObj(obj)
+			if pod == nil {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if remaining.Has(pod.Name) {
+				markDone(pod.Name)
+			}
+		}
+	} else {
+		handlers.AddFunc = onRunning
+		handlers.UpdateFunc = func(_, obj interface{}) { onRunning(obj) }
+	}
+
+	lw := cache.NewListWatchFromClient(c.CoreV1().RESTClient(), "pods", namespace, fields.Everything())
+	store, controller := cache.NewInformer(lw, &v1.Pod{}, 0, handlers)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go controller.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, controller.HasSynced) {
+		return fmt.Errorf("timed out waiting for pod informer to sync in namespace %s", namespace)
+	}
+
+	if waitForDeletion {
+		// Pods deleted before the informer started never produce a delete
+		// event, so reconcile the pending set against the synced store once.
+		lock.Lock()
+		for _, name := range remaining.List() {
+			if _, exists, _ := store.GetByKey(namespace + "/" + name); !exists {
+				markDone(name)
+			}
+		}
+		lock.Unlock()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		lock.Lock()
+		defer lock.Unlock()
+		verb := "running"
+		if waitForDeletion {
+			verb = "deleted"
+		}
+		return fmt.Errorf("timed out after %v waiting for %d pods in namespace %s to be %s: %v", timeout, remaining.Len(), namespace, verb, remaining.List())
+	}
+}
@@ -30,9 +30,14 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
 	"k8s.io/kubectl/pkg/util/podutils"
 	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
 	testutils "k8s.io/kubernetes/test/utils"
@@ -201,29 +206,109 @@ func WaitForPodsRunningReady(c clientset.Interface, ns string, minPods, allowedN
 }
 
 // WaitForPodCondition waits a pods to be matched to the given condition.
+// It watches the pod instead of polling it and, when the timeout expires,
+// appends the pod's events, container statuses and node conditions to the
+// returned error so the failure is actionable without re-running the test.
 func WaitForPodCondition(c clientset.Interface, ns, podName, desc string, timeout time.Duration, condition podCondition) error {
 	e2elog.Logf("Waiting up to %v for pod %q in namespace %q to be %q", timeout, podName, ns, desc)
-	for start := time.Now(); time.Since(start) < timeout; time.Sleep(poll) {
-		pod, err := c.CoreV1().Pods(ns).Get(context.TODO(), podName, metav1.GetOptions{})
+
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), timeout)
+	defer cancel()
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", podName).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return c.CoreV1().Pods(ns).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return c.CoreV1().Pods(ns).Watch(context.TODO(), options)
+		},
+	}
+
+	var lastPod *v1.Pod
+	start := time.Now()
+	// A pod that does not exist when the wait starts is reported as NotFound
+	// immediately, matching the behavior of the previous Get-based loop.
+	preconditionFunc := func(store cache.Store) (bool, error) {
+		_, exists, err := store.Get(&metav1.ObjectMeta{Namespace: ns, Name: podName})
 		if err != nil {
-			if apierrors.IsNotFound(err) {
-				e2elog.Logf("Pod %q in namespace %q not found. Error: %v", podName, ns, err)
-				return err
-			}
-			e2elog.Logf("Get pod %q in namespace %q failed, ignoring for %v. Error: %v", podName, ns, poll, err)
-			continue
+			return true, err
+		}
+		if !exists {
+			e2elog.Logf("Pod %q in namespace %q not found.", podName, ns)
+			return true, apierrors.NewNotFound(v1.Resource("pods"), podName)
 		}
+		return false, nil
+	}
+	_, err := watchtools.UntilWithSync(ctx, lw, &v1.Pod{}, preconditionFunc, func(event watch.Event) (bool, error) {
+		if event.Type == watch.Deleted {
+			e2elog.Logf("Pod %q in namespace %q was deleted.", podName, ns)
+			return true, apierrors.NewNotFound(v1.Resource("pods"), podName)
+		}
+		pod, ok := event.Object.(*v1.Pod)
+		if !ok {
+			return true, fmt.Errorf("unexpected object of type %T while watching pod %q", event.Object, podName)
+		}
+		lastPod = pod
 		// log now so that current pod info is reported before calling `condition()`
 		e2elog.Logf("Pod %q: Phase=%q, Reason=%q, readiness=%t. Elapsed: %v",
 			podName, pod.Status.Phase, pod.Status.Reason, podutils.IsPodReady(pod), time.Since(start))
-		if done, err := condition(pod); done {
-			if err == nil {
-				e2elog.Logf("Pod %q satisfied condition %q", podName, desc)
+		return condition(pod)
+	})
+	if err == nil {
+		e2elog.Logf("Pod %q satisfied condition %q", podName, desc)
+		return nil
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("Gave up after waiting %v for pod %q to be %q:\n%s", timeout, podName, desc, podDebugInfo(c, ns, podName, lastPod))
+	}
+	return err
+}
+
+// podDebugInfo collects the pod's events, its container statuses and the
+// conditions of the node it is bound to into a printable block, to be
+// appended to wait timeout errors.
+func podDebugInfo(c clientset.Interface, ns, podName string, pod *v1.Pod) string {
+	var buf bytes.Buffer
+	if pod == nil {
+		p, err := c.CoreV1().Pods(ns).Get(context.TODO(), podName, metav1.GetOptions{})
+		if err != nil {
+			fmt.Fprintf(&buf, "Could not fetch pod %q for diagnostics: %v\n", podName, err)
+		} else {
+			pod = p
+		}
+	}
+	if pod != nil {
+		fmt.Fprintf(&buf, "Container statuses:\n")
+		for _, s := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			fmt.Fprintf(&buf, "  %s: ready=%t restartCount=%d state=%+v\n", s.Name, s.Ready, s.RestartCount, s.State)
+		}
+	}
+
+	eventSelector := fields.Set{"involvedObject.name": podName, "involvedObject.namespace": ns}.AsSelector().String()
+	events, err := c.CoreV1().Events(ns).List(context.TODO(), metav1.ListOptions{FieldSelector: eventSelector})
+	if err != nil {
+		fmt.Fprintf(&buf, "Could not fetch events for pod %q: %v\n", podName, err)
+	} else {
+		fmt.Fprintf(&buf, "Events:\n")
+		for _, e := range events.Items {
+			fmt.Fprintf(&buf, "  %s %s: %s\n", e.Type, e.Reason, e.Message)
+		}
+	}
+
+	if pod != nil && pod.Spec.NodeName != "" {
+		node, err := c.CoreV1().Nodes().Get(context.TODO(), pod.Spec.NodeName, metav1.GetOptions{})
+		if err != nil {
+			fmt.Fprintf(&buf, "Could not fetch node %q for diagnostics: %v\n", pod.Spec.NodeName, err)
+		} else {
+			fmt.Fprintf(&buf, "Conditions of node %s:\n", node.Name)
+			for _, c := range node.Status.Conditions {
+				fmt.Fprintf(&buf, "  %s=%s (%s): %s\n", c.Type, c.Status, c.Reason, c.Message)
 			}
-			return err
 		}
 	}
-	return fmt.Errorf("Gave up after waiting %v for pod %q to be %q", timeout, podName, desc)
+	return buf.String()
 }
 
 // WaitForPodTerminatedInNamespace returns an error if it takes too long for the pod to terminate,
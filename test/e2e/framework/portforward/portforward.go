@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package portforward establishes port-forward sessions to pods through the
+// API server programmatically, without shelling out to kubectl.
+package portforward
+
+import (
+	"fmt"
+	"net/http"
+
+	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// Session is an established port-forward session. Connections to the local
+// port are tunneled to the remote port of the pod. Close must be called to
+// release the session.
+type Session struct {
+	// LocalPort is the dynamically allocated local listen port.
+	LocalPort uint16
+
+	forwarder *portforward.PortForwarder
+	stopCh    chan struct{}
+	errCh     chan error
+}
+
+// Close terminates the port-forward session and waits for the forwarder to
+// shut down.
+func (s *Session) Close() {
+	close(s.stopCh)
+	if err := <-s.errCh; err != nil {
+		framework.Logf("port-forward session ended with error: %v", err)
+	}
+}
+
+// ForwardPortToPod starts forwarding a dynamically chosen local port to the
+// given remote port of a pod, using the SPDY dialer of the pods/portforward
+// subresource. The returned session is ready for connections.
+func ForwardPortToPod(config *restclient.Config, client clientset.Interface, namespace, podName string, remotePort int) (*Session, error) {
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating spdy round tripper: %v", err)
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	fw, err := portforward.New(dialer, []string{fmt.Sprintf("0:%d", remotePort)}, stopCh, readyCh, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating port forwarder: %v", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return nil, fmt.Errorf("port forwarding failed before becoming ready: %v", err)
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil {
+		return nil, fmt.Errorf("error getting forwarded ports: %v", err)
+	}
+	if len(ports) != 1 {
+		return nil, fmt.Errorf("expected one forwarded port, got %v", ports)
+	}
+
+	return &Session{
+		LocalPort: ports[0].Local,
+		forwarder: fw,
+		stopCh:    stopCh,
+		errCh:     errCh,
+	}, nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package external adapts an out-of-tree provider plugin binary to
+// framework.ProviderInterface, so cloud providers can supply
+// provider-specific behavior (load balancer cleanup, persistent disks,
+// zone information) without patching test/e2e/framework/providers.
+//
+// The plugin is selected with --provider=external and
+// --external-provider-binary=<path>. Each framework call execs the binary
+// with one subcommand and positional arguments, e.g.
+//
+//	<binary> create-pd <zone>
+//	<binary> delete-pd <name>
+//	<binary> create-pv-source <zone> <diskName>
+//	<binary> delete-pv-source        (v1.PersistentVolumeSource JSON on stdin)
+//	<binary> cleanup-service-resources <loadBalancerName> <region> <zone>
+//	<binary> ensure-load-balancer-resources-deleted <ip> <portRange>
+//	<binary> load-balancer-src-ranges
+//
+// Results are printed to stdout: a bare value for create-pd, a
+// v1.PersistentVolumeSource JSON object for create-pv-source and a JSON
+// string array for load-balancer-src-ranges. A plugin that does not
+// implement a subcommand exits with code 2 and the framework falls back to
+// the provider-less default for that call.
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/framework/config"
+)
+
+// notSupportedExitCode is the exit code a plugin uses to report that it does
+// not implement a subcommand.
+const notSupportedExitCode = 2
+
+var providerBinary = config.Flags.String("external-provider-binary", "", "Path to the provider plugin binary used with --provider=external.")
+
+func init() {
+	framework.RegisterProvider("external", newProvider)
+}
+
+func newProvider() (framework.ProviderInterface, error) {
+	if *providerBinary == "" {
+		return nil, fmt.Errorf("--external-provider-binary must be set when --provider=external is used")
+	}
+	path, err := exec.LookPath(*providerBinary)
+	if err != nil {
+		return nil, fmt.Errorf("provider plugin binary %q not found: %v", *providerBinary, err)
+	}
+	return &Provider{binary: path}, nil
+}
+
+// Provider runs a plugin binary for every provider-specific call and falls
+// back to the NullProvider behavior for subcommands the plugin does not
+// implement.
+type Provider struct {
+	framework.NullProvider
+	binary string
+}
+
+// CreatePD asks the plugin to create a persistent disk in the given zone and
+// returns its name.
+func (p *Provider) CreatePD(zone string) (string, error) {
+	out, supported, err := p.run(nil, "create-pd", zone)
+	if err != nil {
+		return "", err
+	}
+	if !supported {
+		return p.NullProvider.CreatePD(zone)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// DeletePD asks the plugin to delete the named persistent disk.
+func (p *Provider) DeletePD(pdName string) error {
+	_, supported, err := p.run(nil, "delete-pd", pdName)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return p.NullProvider.DeletePD(pdName)
+	}
+	return nil
+}
+
+// CreatePVSource asks the plugin for the volume source referencing the disk.
+func (p *Provider) CreatePVSource(zone, diskName string) (*v1.PersistentVolumeSource, error) {
+	out, supported, err := p.run(nil, "create-pv-source", zone, diskName)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		return p.NullProvider.CreatePVSource(zone, diskName)
+	}
+	pvSource := &v1.PersistentVolumeSource{}
+	if err := json.Unmarshal(out, pvSource); err != nil {
+		return nil, fmt.Errorf("decoding create-pv-source output of provider plugin: %v", err)
+	}
+	return pvSource, nil
+}
+
+// DeletePVSource passes the volume source to the plugin for deletion.
+func (p *Provider) DeletePVSource(pvSource *v1.PersistentVolumeSource) error {
+	stdin, err := json.Marshal(pvSource)
+	if err != nil {
+		return err
+	}
+	_, supported, err := p.run(stdin, "delete-pv-source")
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return p.NullProvider.DeletePVSource(pvSource)
+	}
+	return nil
+}
+
+// CleanupServiceResources asks the plugin to remove cloud resources left
+// behind by a load balancer service.
+func (p *Provider) CleanupServiceResources(c clientset.Interface, loadBalancerName, region, zone string) {
+	if _, _, err := p.run(nil, "cleanup-service-resources", loadBalancerName, region, zone); err != nil {
+		framework.Logf("Failed to clean up service resources for %s: %v", loadBalancerName, err)
+	}
+}
+
+// EnsureLoadBalancerResourcesDeleted asks the plugin to verify load balancer
+// resources for the given ip and port range are gone.
+func (p *Provider) EnsureLoadBalancerResourcesDeleted(ip, portRange string) error {
+	_, supported, err := p.run(nil, "ensure-load-balancer-resources-deleted", ip, portRange)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return p.NullProvider.EnsureLoadBalancerResourcesDeleted(ip, portRange)
+	}
+	return nil
+}
+
+// LoadBalancerSrcRanges returns the plugin's source ranges for load balancer
+// health checks and traffic.
+func (p *Provider) LoadBalancerSrcRanges() []string {
+	out, supported, err := p.run(nil, "load-balancer-src-ranges")
+	if err != nil || !supported {
+		if err != nil {
+			framework.Logf("Failed to query load balancer source ranges: %v", err)
+		}
+		return p.NullProvider.LoadBalancerSrcRanges()
+	}
+	var ranges []string
+	if err := json.Unmarshal(out, &ranges); err != nil {
+		framework.Logf("Failed to decode load balancer source ranges %q: %v", string(out), err)
+		return p.NullProvider.LoadBalancerSrcRanges()
+	}
+	return ranges
+}
+
+// run execs the plugin with the given subcommand and arguments, returning
+// its stdout. supported is false when the plugin reported the subcommand as
+// not implemented.
+func (p *Provider) run(stdin []byte, args ...string) (out []byte, supported bool, err error) {
+	cmd := exec.Command(p.binary, args...)
+	var stdout, stderr bytes.Buffer
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == notSupportedExitCode {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("provider plugin %q failed: %v, stderr: %q", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.Bytes(), true, nil
+}
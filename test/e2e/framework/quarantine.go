@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/onsi/ginkgo"
+)
+
+var (
+	quarantineOnce     sync.Once
+	quarantinePatterns []*regexp.Regexp
+)
+
+// loadQuarantineList parses TestContext.QuarantineListFile into regular
+// expressions. Blank lines and lines starting with # are ignored; lines that
+// do not compile are logged and skipped so one bad entry does not disable the
+// whole list.
+func loadQuarantineList() {
+	file, err := os.Open(TestContext.QuarantineListFile)
+	if err != nil {
+		Logf("Could not read quarantine list file %q: %v", TestContext.QuarantineListFile, err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			Logf("Ignoring invalid quarantine list entry %q: %v", line, err)
+			continue
+		}
+		quarantinePatterns = append(quarantinePatterns, pattern)
+	}
+	Logf("Loaded %d quarantine entries from %q", len(quarantinePatterns), TestContext.QuarantineListFile)
+}
+
+// currentSpecQuarantined reports whether the currently running spec matches
+// an entry of the quarantine list. It always returns false when no quarantine
+// list file is configured.
+func currentSpecQuarantined() bool {
+	if TestContext.QuarantineListFile == "" {
+		return false
+	}
+	quarantineOnce.Do(loadQuarantineList)
+	name := ginkgo.CurrentGinkgoTestDescription().FullTestText
+	for _, pattern := range quarantinePatterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -167,10 +167,14 @@ func leftMergeData(left, right map[int]ResourceUsagePerContainer) map[int]Resour
 }
 
 type resourceGatherWorker struct {
-	c                           clientset.Interface
-	nodeName                    string
-	wg                          *sync.WaitGroup
-	containerIDs                []string
+	c            clientset.Interface
+	nodeName     string
+	wg           *sync.WaitGroup
+	containerIDs []string
+	// containerNames, when set, is consulted at every probe instead of the
+	// static containerIDs, so containers created after the worker started
+	// are picked up too.
+	containerNames              func() []string
 	stopCh                      chan struct{}
 	dataSeries                  []ResourceUsagePerContainer
 	finished                    bool
@@ -195,7 +199,11 @@ func (w *resourceGatherWorker) singleProbe() {
 			}
 		}
 	} else {
-		nodeUsage, err := getOneTimeResourceUsageOnNode(w.c, w.nodeName, w.probeDuration, func() []string { return w.containerIDs })
+		containerNames := w.containerNames
+		if containerNames == nil {
+			containerNames = func() []string { return w.containerIDs }
+		}
+		nodeUsage, err := getOneTimeResourceUsageOnNode(w.c, w.nodeName, w.probeDuration, containerNames)
 		if err != nil {
 			Logf("Error while reading data from %v: %v", w.nodeName, err)
 			return
@@ -556,6 +564,68 @@ func (g *ContainerResourceGatherer) StopAndSummarize(percentiles []int, constrai
 	return &summary, nil
 }
 
+// TestPodResourceUsageSummary separates the usage of a spec's own pods from
+// the kube-system component usage in the report output.
+type TestPodResourceUsageSummary struct {
+	ResourceUsageSummary
+}
+
+// SummaryKind returns string of TestPodResourceUsageSummary
+func (s *TestPodResourceUsageSummary) SummaryKind() string {
+	return "TestPodResourceUsageSummary"
+}
+
+// NewTestPodResourceGatherer returns a gatherer tracking the containers of the
+// pods in the given namespace. The pod set is resolved anew at every probe, so
+// pods the spec creates after the gatherer has started are picked up too.
+func NewTestPodResourceGatherer(c clientset.Interface, namespace string) (*ContainerResourceGatherer, error) {
+	options := ResourceGathererOptions{
+		Nodes:                       AllNodes,
+		ResourceDataGatheringPeriod: 15 * time.Second,
+		ProbeDuration:               5 * time.Second,
+	}
+	g := ContainerResourceGatherer{
+		client:  c,
+		stopCh:  make(chan struct{}),
+		options: options,
+	}
+	containerNames := func() []string {
+		pods, err := c.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			Logf("Error while listing Pods in %v: %v", namespace, err)
+			return nil
+		}
+		names := []string{}
+		for _, pod := range pods.Items {
+			for _, container := range pod.Spec.InitContainers {
+				names = append(names, container.Name)
+			}
+			for _, container := range pod.Spec.Containers {
+				names = append(names, container.Name)
+			}
+		}
+		return names
+	}
+	nodeList, err := c.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		Logf("Error while listing Nodes: %v", err)
+		return nil, err
+	}
+	for _, node := range nodeList.Items {
+		g.workerWg.Add(1)
+		g.workers = append(g.workers, resourceGatherWorker{
+			c:                           c,
+			nodeName:                    node.Name,
+			wg:                          &g.workerWg,
+			containerNames:              containerNames,
+			stopCh:                      g.stopCh,
+			resourceDataGatheringPeriod: options.ResourceDataGatheringPeriod,
+			probeDuration:               options.ProbeDuration,
+		})
+	}
+	return &g, nil
+}
+
 // kubemarkResourceUsage is a struct for tracking the resource usage of kubemark.
 type kubemarkResourceUsage struct {
 	Name                    string
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scheduler provides a harness for e2e tests that need an additional
+// scheduler running with a custom KubeSchedulerConfiguration, e.g. to verify
+// the behavior of scheduling profiles or plugin weights.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2eauth "k8s.io/kubernetes/test/e2e/framework/auth"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+)
+
+const (
+	configFileName  = "config.yaml"
+	configMountPath = "/etc/kubernetes"
+)
+
+// SimpleConfig returns a minimal KubeSchedulerConfiguration with a single
+// profile handling pods that set the given schedulerName. Tests that need
+// custom plugin weights or additional profiles supply their own config
+// instead.
+func SimpleConfig(schedulerName string) string {
+	return fmt.Sprintf(`apiVersion: kubescheduler.config.k8s.io/v1alpha2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- schedulerName: %s
+`, schedulerName)
+}
+
+// RunCustomScheduler deploys a second scheduler as a pod in the framework's
+// namespace, running the cluster's own kube-scheduler image with the given
+// KubeSchedulerConfiguration, and waits for it to be running. The scheduler
+// uses the in-cluster config with a service account bound to the bootstrap
+// scheduler roles. All namespaced resources it creates are cleaned up with
+// the namespace.
+func RunCustomScheduler(f *framework.Framework, name, config string) (*v1.Pod, error) {
+	cs := f.ClientSet
+	ns := f.Namespace.Name
+
+	image, err := schedulerImage(cs)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = cs.CoreV1().ServiceAccounts(ns).Create(context.TODO(), &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account for scheduler %q: %v", name, err)
+	}
+	subject := rbacv1.Subject{
+		Kind:      rbacv1.ServiceAccountKind,
+		Namespace: ns,
+		Name:      name,
+	}
+	for _, clusterRole := range []string{"system:kube-scheduler", "system:volume-scheduler"} {
+		if err := e2eauth.BindClusterRole(cs.RbacV1(), clusterRole, ns, subject); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = cs.CoreV1().ConfigMaps(ns).Create(context.TODO(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Data:       map[string]string{configFileName: config},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config for scheduler %q: %v", name, err)
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PodSpec{
+			ServiceAccountName: name,
+			Containers: []v1.Container{
+				{
+					Name:  "kube-scheduler",
+					Image: image,
+					Command: []string{
+						"kube-scheduler",
+						fmt.Sprintf("--config=%s/%s", configMountPath, configFileName),
+						// The test service account cannot read the
+						// extension-apiserver authentication configmap.
+						"--authentication-skip-lookup=true",
+					},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      "config",
+							MountPath: configMountPath,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "config",
+					VolumeSource: v1.VolumeSource{
+						ConfigMap: &v1.ConfigMapVolumeSource{
+							LocalObjectReference: v1.LocalObjectReference{Name: name},
+						},
+					},
+				},
+			},
+		},
+	}
+	pod, err = cs.CoreV1().Pods(ns).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler pod %q: %v", name, err)
+	}
+	if err := e2epod.WaitForPodNameRunningInNamespace(cs, pod.Name, ns); err != nil {
+		return nil, fmt.Errorf("scheduler pod %q never became running: %v", name, err)
+	}
+	return pod, nil
+}
+
+// schedulerImage derives the kube-scheduler image matching the cluster's
+// control plane version.
+func schedulerImage(cs clientset.Interface) (string, error) {
+	version, err := cs.Discovery().ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("failed to discover server version: %v", err)
+	}
+	// Build metadata is not part of the published image tags.
+	tag := strings.SplitN(version.GitVersion, "+", 2)[0]
+	return "k8s.gcr.io/kube-scheduler:" + tag, nil
+}
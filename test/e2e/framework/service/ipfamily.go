@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	netutils "k8s.io/utils/net"
+)
+
+// VerifyServiceIPFamily returns an error unless both the declared spec.ipFamily
+// of the service and its allocated cluster IP belong to the given family.
+func VerifyServiceIPFamily(svc *v1.Service, family v1.IPFamily) error {
+	if svc.Spec.IPFamily == nil {
+		return fmt.Errorf("service %s/%s has no ip family set", svc.Namespace, svc.Name)
+	}
+	if *svc.Spec.IPFamily != family {
+		return fmt.Errorf("service %s/%s has ip family %s, expected %s", svc.Namespace, svc.Name, *svc.Spec.IPFamily, family)
+	}
+	if svc.Spec.ClusterIP == v1.ClusterIPNone {
+		return nil
+	}
+	if (family == v1.IPv6Protocol) != netutils.IsIPv6String(svc.Spec.ClusterIP) {
+		return fmt.Errorf("service %s/%s has cluster IP %s, which does not belong to the %s family", svc.Namespace, svc.Name, svc.Spec.ClusterIP, family)
+	}
+	return nil
+}
+
+// VerifyEndpointsIPFamily returns an error if any ready endpoint address does
+// not belong to the given IP family, or if the endpoints have no subsets at
+// all.
+func VerifyEndpointsIPFamily(endpoints *v1.Endpoints, family v1.IPFamily) error {
+	if len(endpoints.Subsets) == 0 {
+		return fmt.Errorf("endpoints %s/%s have no subsets", endpoints.Namespace, endpoints.Name)
+	}
+	for _, ss := range endpoints.Subsets {
+		for _, address := range ss.Addresses {
+			if (family == v1.IPv6Protocol) != netutils.IsIPv6String(address.IP) {
+				return fmt.Errorf("endpoint address %s of %s/%s does not belong to the %s family", address.IP, endpoints.Namespace, endpoints.Name, family)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"sync"
+
+	"github.com/onsi/ginkgo"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+var (
+	serverVersionOnce   sync.Once
+	cachedServerVersion *version.Info
+	serverVersionErr    error
+)
+
+// ServerVersion returns the version of the server under test. The apiserver
+// is queried once and the result cached, so per-spec version gating does not
+// add a discovery request to every spec.
+func ServerVersion() (*version.Info, error) {
+	serverVersionOnce.Do(func() {
+		client, err := LoadClientset()
+		if err != nil {
+			serverVersionErr = err
+			return
+		}
+		cachedServerVersion, serverVersionErr = client.Discovery().ServerVersion()
+	})
+	return cachedServerVersion, serverVersionErr
+}
+
+// ServerVersionRange constrains a spec to a window of server versions in
+// version-skew jobs. A nil bound leaves that side unconstrained.
+type ServerVersionRange struct {
+	// MinVersion is the lowest server version the spec supports, inclusive.
+	MinVersion *utilversion.Version
+	// MaxVersion is the first server version the spec no longer supports, exclusive.
+	MaxVersion *utilversion.Version
+}
+
+// skipUnlessServerVersionInRange skips the current spec when the server
+// version falls outside the range.
+func skipUnlessServerVersionInRange(r ServerVersionRange) {
+	if r.MinVersion == nil && r.MaxVersion == nil {
+		return
+	}
+	info, err := ServerVersion()
+	if err != nil {
+		Failf("Failed to get server version: %v", err)
+	}
+	sv, err := utilversion.ParseSemantic(info.GitVersion)
+	if err != nil {
+		Failf("Failed to parse server version %q: %v", info.GitVersion, err)
+	}
+	if r.MinVersion != nil && sv.LessThan(r.MinVersion) {
+		msg := "Not supported for server versions before " + r.MinVersion.String()
+		Logf(msg)
+		ginkgo.Skip(msg, 2)
+	}
+	if r.MaxVersion != nil && sv.AtLeast(r.MaxVersion) {
+		msg := "Not supported for server versions at or above " + r.MaxVersion.String()
+		Logf(msg)
+		ginkgo.Skip(msg, 2)
+	}
+}
+
+// VersionedIt is a wrapper function for ginkgo It which skips the spec at
+// runtime when the server version is outside the given range, so version-skew
+// CI jobs do not fail on specs for features the server does not have yet.
+func VersionedIt(text string, r ServerVersionRange, body func(), timeout ...float64) bool {
+	return ginkgo.It(text, func() {
+		skipUnlessServerVersionInRange(r)
+		body()
+	}, timeout...)
+}
+
+// VersionedConformanceIt is VersionedIt with the "[Conformance]" tag appended,
+// mirroring ConformanceIt.
+func VersionedConformanceIt(text string, r ServerVersionRange, body func(), timeout ...float64) bool {
+	return VersionedIt(text+" [Conformance]", r, body, timeout...)
+}
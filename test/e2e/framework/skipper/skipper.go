@@ -149,6 +149,61 @@ func SkipIfMissingResource(dynamicClient dynamic.Interface, gvr schema.GroupVers
 	}
 }
 
+// SkipUnlessAPIGroupVersionServed skips if the server does not serve the given group version.
+func SkipUnlessAPIGroupVersionServed(c discovery.DiscoveryInterface, gv schema.GroupVersion) {
+	if _, err := c.ServerResourcesForGroupVersion(gv.String()); err != nil {
+		if apierrors.IsNotFound(err) {
+			skipInternalf(1, "Could not find group version %s, skipping test", gv)
+		}
+		framework.Failf("Unexpected error discovering group version %s: %v", gv, err)
+	}
+}
+
+// SkipUnlessResourceServed skips if the server does not serve the given resource,
+// e.g. because the API is alpha or gated off on this cluster. Unlike
+// SkipIfMissingResource it only consults discovery and therefore also works
+// for resources that do not support list.
+func SkipUnlessResourceServed(c discovery.DiscoveryInterface, gvr schema.GroupVersionResource) {
+	resources, err := c.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			skipInternalf(1, "Could not find group version %s, skipping test", gvr.GroupVersion())
+		}
+		framework.Failf("Unexpected error discovering group version %s: %v", gvr.GroupVersion(), err)
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return
+		}
+	}
+	skipInternalf(1, "Could not find %s resource, skipping test", gvr)
+}
+
+// featureGateEnabled reports whether the cluster under test has the gate
+// enabled. The --feature-gates flag describes the cluster's configuration;
+// gates not mentioned there fall back to their in-tree default. The gate must
+// be known to this build.
+func featureGateEnabled(gate featuregate.Feature) bool {
+	if enabled, ok := framework.TestContext.FeatureGates[string(gate)]; ok {
+		return enabled
+	}
+	return utilfeature.DefaultFeatureGate.Enabled(gate)
+}
+
+// SkipUnlessFeatureGateEnabled skips if the gate is disabled on the cluster under test.
+func SkipUnlessFeatureGateEnabled(gate featuregate.Feature) {
+	if !featureGateEnabled(gate) {
+		skipInternalf(1, "Only supported when %v feature is enabled", gate)
+	}
+}
+
+// SkipIfFeatureGateEnabled skips if the gate is enabled on the cluster under test.
+func SkipIfFeatureGateEnabled(gate featuregate.Feature) {
+	if featureGateEnabled(gate) {
+		skipInternalf(1, "Not supported when %v feature is enabled", gate)
+	}
+}
+
 // SkipUnlessNodeCountIsAtLeast skips if the number of nodes is less than the minNodeCount.
 func SkipUnlessNodeCountIsAtLeast(minNodeCount int) {
 	if framework.TestContext.CloudConfig.NumNodes < minNodeCount {
@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	uexec "k8s.io/client-go/util/exec"
+	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+)
+
+// NodeExecutor runs a command on a node. When one is registered with
+// SetNodeExecutor, IssueSSHCommand and IssueSSHCommandWithResult use it
+// instead of SSH, so node-dependent helpers keep working on clusters where
+// SSH to nodes is impossible.
+type NodeExecutor interface {
+	// Exec runs cmd on the node and returns its output and exit code.
+	Exec(cmd string, node *v1.Node) (Result, error)
+}
+
+var nodeExecutor NodeExecutor
+
+// SetNodeExecutor replaces SSH as the transport used by IssueSSHCommand and
+// IssueSSHCommandWithResult. Passing nil restores SSH.
+func SetNodeExecutor(executor NodeExecutor) {
+	nodeExecutor = executor
+}
+
+// hostPodExecutor is the default SSH-less NodeExecutor. It lazily runs one
+// privileged pod per node, entering the host mount namespace with nsenter,
+// which has the same effect as a DaemonSet of host-namespace pods but only
+// touches the nodes that are actually used.
+type hostPodExecutor struct {
+	client    clientset.Interface
+	config    *restclient.Config
+	namespace string
+
+	lock sync.Mutex
+	pods map[string]*v1.Pod
+}
+
+// NewHostPodExecutor returns a NodeExecutor that runs node commands in
+// privileged host-namespace pods created in the given namespace. The caller
+// is responsible for calling Cleanup when the suite is done with it.
+func NewHostPodExecutor(client clientset.Interface, config *restclient.Config, namespace string) *hostPodExecutor {
+	return &hostPodExecutor{
+		client:    client,
+		config:    config,
+		namespace: namespace,
+		pods:      make(map[string]*v1.Pod),
+	}
+}
+
+// Exec runs cmd in the host mount namespace of the node.
+func (e *hostPodExecutor) Exec(cmd string, node *v1.Node) (Result, error) {
+	result := Result{Host: node.Name, Cmd: cmd}
+	pod, err := e.podForNode(node.Name)
+	if err != nil {
+		return result, err
+	}
+	args := []string{
+		"nsenter",
+		"--mount=/rootfs/proc/1/ns/mnt",
+		"--",
+		"sh",
+		"-c",
+		cmd,
+	}
+	result.Stdout, result.Stderr, err = e.execInPod(pod, args)
+	if codeExitErr, ok := err.(uexec.CodeExitError); ok {
+		result.Code = codeExitErr.ExitStatus()
+		err = nil
+	}
+	return result, err
+}
+
+// Cleanup deletes the node access pods the executor created.
+func (e *hostPodExecutor) Cleanup() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, pod := range e.pods {
+		if err := e.client.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, *metav1.NewDeleteOptions(0)); err != nil {
+			e2elog.Logf("Failed to delete node access pod %s: %v", pod.Name, err)
+		}
+	}
+	e.pods = make(map[string]*v1.Pod)
+}
+
+// podForNode returns the running node access pod for the node, creating it
+// on first use.
+func (e *hostPodExecutor) podForNode(nodeName string) (*v1.Pod, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if pod, ok := e.pods[nodeName]; ok {
+		return pod, nil
+	}
+
+	hostExecPod := e2epod.NewExecPodSpec(e.namespace, "", true)
+	hostExecPod.GenerateName = fmt.Sprintf("node-exec-%s-", nodeName)
+	hostExecPod.Spec.NodeName = nodeName
+	hostExecPod.Spec.Tolerations = []v1.Toleration{
+		{Operator: v1.TolerationOpExists},
+	}
+	hostExecPod.Spec.Volumes = []v1.Volume{
+		{
+			// Required to enter into host mount namespace via nsenter.
+			Name: "rootfs",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: "/",
+				},
+			},
+		},
+	}
+	hostExecPod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{
+		{
+			Name:      "rootfs",
+			MountPath: "/rootfs",
+			ReadOnly:  true,
+		},
+	}
+	privileged := true
+	hostExecPod.Spec.Containers[0].SecurityContext = &v1.SecurityContext{
+		Privileged: &privileged,
+	}
+
+	pod, err := e.client.CoreV1().Pods(e.namespace).Create(context.TODO(), hostExecPod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create node access pod for node %q: %v", nodeName, err)
+	}
+	if err := e2epod.WaitForPodRunningInNamespace(e.client, pod); err != nil {
+		return nil, fmt.Errorf("node access pod %s never became running: %v", pod.Name, err)
+	}
+	e.pods[nodeName] = pod
+	return pod, nil
+}
+
+// execInPod runs the command in the pod's first container, like
+// Framework.ExecWithOptions but without depending on a Framework instance.
+func (e *hostPodExecutor) execInPod(pod *v1.Pod, command []string) (string, string, error) {
+	req := e.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: pod.Spec.Containers[0].Name,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return "", "", err
+	}
+	var stdout, stderr bytes.Buffer
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	return stdout.String(), stderr.String(), err
+}
@@ -324,8 +324,20 @@ func LogResult(result Result) {
 	e2elog.Logf("ssh %s: exit code: %d", remote, result.Code)
 }
 
-// IssueSSHCommandWithResult tries to execute a SSH command and returns the execution result
+// IssueSSHCommandWithResult tries to execute a SSH command and returns the execution result.
+// When a NodeExecutor is registered via SetNodeExecutor, the command runs through it instead.
 func IssueSSHCommandWithResult(cmd, provider string, node *v1.Node) (*Result, error) {
+	if nodeExecutor != nil {
+		e2elog.Logf("Exec %q on %s via node executor", cmd, node.Name)
+		result, err := nodeExecutor.Exec(cmd, node)
+		LogResult(result)
+		if result.Code != 0 || err != nil {
+			return nil, fmt.Errorf("failed running %q: %v (exit code %d, stderr %v)",
+				cmd, err, result.Code, result.Stderr)
+		}
+		return &result, nil
+	}
+
 	e2elog.Logf("Getting external IP address for %s", node.Name)
 	host := ""
 	for _, a := range node.Status.Addresses {
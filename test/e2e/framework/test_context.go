@@ -33,6 +33,8 @@ import (
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog"
 	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	imageutils "k8s.io/kubernetes/test/utils/image"
 )
 
 const (
@@ -125,11 +127,14 @@ type TestContextType struct {
 	// It will read the data every 30 seconds from all Nodes and print summary during afterEach. If set to 'master'
 	// only master Node will be monitored.
 	GatherKubeSystemResourceUsageData string
-	GatherLogsSizes                   bool
-	GatherMetricsAfterTest            string
-	GatherSuiteMetricsAfterTest       bool
-	MaxNodesToGather                  int
-	AllowGatheringProfiles            bool
+	// If set to true, the framework will sample CPU/memory of the pods each
+	// spec creates in its namespace and attach a usage summary to the report.
+	GatherTestPodResourceUsageData bool
+	GatherLogsSizes                bool
+	GatherMetricsAfterTest         string
+	GatherSuiteMetricsAfterTest    bool
+	MaxNodesToGather               int
+	AllowGatheringProfiles         bool
 	// If set to 'true' framework will gather ClusterAutoscaler metrics when gathering them for other components.
 	IncludeClusterAutoscalerMetrics bool
 	// Currently supported values are 'hr' for human-readable and 'json'. It's a comma separated list.
@@ -159,6 +164,9 @@ type TestContextType struct {
 	// The configuration of NodeKiller.
 	NodeKiller NodeKillerConfig
 
+	// The configuration of the disruption injector.
+	Disruption DisruptionConfig
+
 	// The Default IP Family of the cluster ("ipv4" or "ipv6")
 	IPFamily string
 
@@ -176,6 +184,74 @@ type TestContextType struct {
 
 	// DockerConfigFile is a file that contains credentials which can be used to pull images from certain private registries, needed for a test.
 	DockerConfigFile string
+
+	// FlakeAttempts is the number of times to attempt each spec before reporting it as failed.
+	// Values above 1 enable retries; specs that fail and then pass are reported as flaked.
+	FlakeAttempts int
+
+	// QuarantineListFile is the path to a file of regular expressions, one per line,
+	// naming known-flaky specs. Matching specs still run, but their failures are
+	// reported as skipped instead of failing the suite.
+	QuarantineListFile string
+
+	// HeartbeatInterval is how often to log (and POST to the progress report URL,
+	// if one is set) the currently executing spec and By step. Zero disables heartbeats.
+	HeartbeatInterval time.Duration
+
+	// RecordAPICalls enables recording the verb and path of every API request a
+	// spec makes and attaching the list to the spec's output.
+	RecordAPICalls bool
+
+	// RecordAPILatency enables measuring the client-observed latency of every
+	// API request across the run and writing a perf-dash-compatible summary.
+	RecordAPILatency bool
+
+	// ClientQPS overrides the per-suite default client QPS when greater than zero.
+	ClientQPS float64
+	// ClientBurst overrides the per-suite default client burst when greater than zero.
+	ClientBurst int
+
+	// ReuseNamespace makes the framework use one persistent, deterministically
+	// named namespace per base name instead of a fresh random one, for
+	// iterating on a single spec during development. Implies that namespaces
+	// are not deleted after the spec.
+	ReuseNamespace bool
+	// SkipNamespaceDeletion keeps namespaces (and the objects in them) around
+	// after the spec for inspection, regardless of the delete-namespace flags.
+	SkipNamespaceDeletion bool
+
+	// InCluster is true when neither --kubeconfig nor --host were supplied
+	// and the binary fell back to the in-cluster config of the pod it runs
+	// in, e.g. when the suite is started as a Job on the cluster under test.
+	// It is set by AfterReadingAllFlags, not by a flag.
+	InCluster bool
+	// ArtifactsUpload is where to publish the contents of ReportDir once the
+	// suite finishes: either a directory (e.g. the mount point of a PVC) or
+	// an http(s) URL receiving a gzipped tar archive via PUT.
+	ArtifactsUpload string
+	// NodeExecMode selects how helpers run commands on nodes: "ssh" (the
+	// default) or "pod", which uses privileged host-namespace pods and works
+	// on clusters where SSH to nodes is impossible.
+	NodeExecMode string
+	// ImageRepoList is a YAML or JSON file mapping the upstream image
+	// registries to mirrors, for running the suite without access to the
+	// public registries. The flag equivalent of the KUBE_TEST_REPO_LIST
+	// environment variable.
+	ImageRepoList string
+	// ImagePullSecretFile is a .dockerconfigjson file with credentials for
+	// the mirrors in ImageRepoList. When set, the framework creates an image
+	// pull secret from it in every test namespace and attaches the secret to
+	// the namespace's default service account.
+	ImagePullSecretFile string
+	// PrepullImages makes the suite pull all test images onto every node
+	// before any spec runs, so that timing-sensitive specs don't flake on
+	// slow image pulls.
+	PrepullImages bool
+	// VerifyImageManifests makes suite setup resolve every test image tag
+	// to a digest and check that images published as manifest lists cover
+	// the cluster's node architectures, failing the suite up front instead
+	// of with ErrImagePull in the middle of a test.
+	VerifyImageManifests bool
 }
 
 // NodeKillerConfig describes configuration of NodeKiller -- a utility to
@@ -197,6 +273,28 @@ type NodeKillerConfig struct {
 	NodeKillerStopCh chan struct{}
 }
 
+// DisruptionConfig describes configuration of the disruption injector -- a
+// utility to inject failures while the suite runs.
+type DisruptionConfig struct {
+	// Enabled determines whether the injector should do anything at all.
+	// All other options below are ignored if Enabled = false.
+	Enabled bool
+	// RestartAPIServer enables killing the kube-apiserver process (where the provider supports it).
+	RestartAPIServer bool
+	// KillTestPods enables force-deleting a random running pod from the test namespaces.
+	KillTestPods bool
+	// CordonNodes enables cordoning a random schedulable node for CordonDuration.
+	CordonNodes bool
+	// Interval is time between disruptions.
+	Interval time.Duration
+	// JitterFactor is factor used to jitter disruptions.
+	JitterFactor float64
+	// CordonDuration is how long a cordoned node stays unschedulable.
+	CordonDuration time.Duration
+	// DisruptionStopCh is a channel that is used to notify the injector to stop.
+	DisruptionStopCh chan struct{}
+}
+
 // NodeTestContextType is part of TestContextType, it is shared by all node e2e test.
 type NodeTestContextType struct {
 	// NodeE2E indicates whether it is running node e2e.
@@ -271,6 +369,7 @@ func RegisterCommonFlags(flags *flag.FlagSet) {
 	config.GinkgoConfig.RandomizeAllSpecs = true
 
 	flags.StringVar(&TestContext.GatherKubeSystemResourceUsageData, "gather-resource-usage", "false", "If set to 'true' or 'all' framework will be monitoring resource usage of system all add-ons in (some) e2e tests, if set to 'master' framework will be monitoring master node only, if set to 'none' of 'false' monitoring will be turned off.")
+	flags.BoolVar(&TestContext.GatherTestPodResourceUsageData, "gather-test-pod-resource-usage", false, "If set to true framework will sample CPU and memory usage of the pods each spec creates in its namespace and attach a per-spec usage summary to the report.")
 	flags.BoolVar(&TestContext.GatherLogsSizes, "gather-logs-sizes", false, "If set to true framework will be monitoring logs sizes on all machines running e2e tests.")
 	flags.IntVar(&TestContext.MaxNodesToGather, "max-nodes-to-gather-from", 20, "The maximum number of nodes to gather extended info from on test failure.")
 	flags.StringVar(&TestContext.GatherMetricsAfterTest, "gather-metrics-at-teardown", "false", "If set to 'true' framework will gather metrics from all components after each test. If set to 'master' only master component metrics would be gathered.")
@@ -306,6 +405,21 @@ func RegisterCommonFlags(flags *flag.FlagSet) {
 	flags.StringVar(&TestContext.ProgressReportURL, "progress-report-url", "", "The URL to POST progress updates to as the suite runs to assist in aiding integrations. If empty, no messages sent.")
 	flags.StringVar(&TestContext.SpecSummaryOutput, "spec-dump", "", "The file to dump all ginkgo.SpecSummary to after tests run. If empty, no objects are saved/printed.")
 	flags.StringVar(&TestContext.DockerConfigFile, "docker-config-file", "", "A file that contains credentials which can be used to pull images from certain private registries, needed for a test.")
+	flags.IntVar(&TestContext.FlakeAttempts, "flake-attempts", 1, "Number of times to attempt each spec before reporting it as failed. Values above 1 enable retries; specs that fail and then pass are reported as flaked but do not fail the suite.")
+	flags.StringVar(&TestContext.QuarantineListFile, "quarantine-list-file", "", "Path to a file of regular expressions, one per line, naming known-flaky specs. Matching specs still run, but their failures are reported as skipped instead of failing the suite. Lines starting with # are ignored.")
+	flags.DurationVar(&TestContext.HeartbeatInterval, "heartbeat-interval", 0, "How often to log the currently executing spec and By step, and POST them to the progress report URL if one is set. If zero, no heartbeats are emitted.")
+	flags.BoolVar(&TestContext.RecordAPICalls, "record-api-calls", false, "If true, record the verb and path of every API request each spec makes and attach the list to the spec's output.")
+	flags.BoolVar(&TestContext.RecordAPILatency, "record-api-latency", false, "If true, measure the client-observed latency of every API request across the run and write an APIResponsiveness summary at the end of the suite.")
+	flags.Float64Var(&TestContext.ClientQPS, "client-qps", 0, "If greater than zero, overrides the default client QPS used by the test framework.")
+	flags.IntVar(&TestContext.ClientBurst, "client-burst", 0, "If greater than zero, overrides the default client burst used by the test framework.")
+	flags.BoolVar(&TestContext.ReuseNamespace, "reuse-namespace", false, "If true, use one persistent deterministically-named namespace per test base name instead of a fresh random one, and never delete it. Only intended for iterating on a single spec during development.")
+	flags.BoolVar(&TestContext.SkipNamespaceDeletion, "skip-namespace-deletion", false, "If true, keep test namespaces and their objects around after each spec for inspection. Only intended for test debugging.")
+	flags.StringVar(&TestContext.NodeExecMode, "node-exec-mode", "ssh", "How to run commands on nodes: \"ssh\", or \"pod\" to use privileged host-namespace pods on clusters where SSH to nodes is impossible.")
+	flags.StringVar(&TestContext.ArtifactsUpload, "artifacts-upload", "", "Where to publish the contents of --report-dir after the suite finishes: a directory such as the mount point of a PVC, or an http(s) URL that receives a gzipped tar archive via PUT. Useful when the suite runs as a Job in the cluster and the pod filesystem is ephemeral.")
+	flags.StringVar(&TestContext.ImageRepoList, "image-repo-list", "", "A YAML or JSON file mapping the upstream image registries to mirrors, for air-gapped clusters without access to the public registries. Registries not listed in the file keep their defaults. Equivalent to the KUBE_TEST_REPO_LIST environment variable.")
+	flags.StringVar(&TestContext.ImagePullSecretFile, "image-pull-secret-file", "", "A .dockerconfigjson file with credentials for the registries in --image-repo-list. If set, an image pull secret built from it is created in every test namespace and attached to the default service account there.")
+	flags.BoolVar(&TestContext.PrepullImages, "prepull-images", false, "If true, pull all test images onto every node before running any specs, so that timing-sensitive specs don't flake on slow image pulls.")
+	flags.BoolVar(&TestContext.VerifyImageManifests, "verify-image-manifests", false, "If true, resolve every test image tag to a digest before running any specs and check that multi-arch images cover the cluster's node architectures, failing the suite up front with a report of the unavailable images.")
 }
 
 // RegisterClusterFlags registers flags specific to the cluster e2e test suite.
@@ -360,6 +474,15 @@ func RegisterClusterFlags(flags *flag.FlagSet) {
 	flags.DurationVar(&nodeKiller.Interval, "node-killer-interval", 1*time.Minute, "Time between node failures.")
 	flags.Float64Var(&nodeKiller.JitterFactor, "node-killer-jitter-factor", 60, "Factor used to jitter node failures.")
 	flags.DurationVar(&nodeKiller.SimulatedDowntime, "node-killer-simulated-downtime", 10*time.Minute, "A delay between node death and recreation")
+
+	disruption := &TestContext.Disruption
+	flags.BoolVar(&disruption.Enabled, "disruption-injector", false, "Whether the disruption injector should inject any disruptions.")
+	flags.BoolVar(&disruption.RestartAPIServer, "disruption-restart-apiserver", false, "Whether the disruption injector may restart the kube-apiserver. Only supported on some providers.")
+	flags.BoolVar(&disruption.KillTestPods, "disruption-kill-test-pods", false, "Whether the disruption injector may force-delete random running test pods.")
+	flags.BoolVar(&disruption.CordonNodes, "disruption-cordon-nodes", false, "Whether the disruption injector may temporarily cordon random nodes.")
+	flags.DurationVar(&disruption.Interval, "disruption-interval", 5*time.Minute, "Time between injected disruptions.")
+	flags.Float64Var(&disruption.JitterFactor, "disruption-jitter-factor", 10, "Factor used to jitter disruptions.")
+	flags.DurationVar(&disruption.CordonDuration, "disruption-cordon-duration", 2*time.Minute, "How long a node cordoned by the disruption injector stays unschedulable.")
 }
 
 func createKubeConfig(clientCfg *restclient.Config) *clientcmdapi.Config {
@@ -411,6 +534,7 @@ func AfterReadingAllFlags(t *TestContextType) {
 				kubeConfig := createKubeConfig(clusterConfig)
 				clientcmd.WriteToFile(*kubeConfig, tempFile.Name())
 				t.KubeConfig = tempFile.Name()
+				t.InCluster = true
 				klog.Infof("Using a temporary kubeconfig file from in-cluster config : %s", tempFile.Name())
 			}
 		}
@@ -424,6 +548,20 @@ func AfterReadingAllFlags(t *TestContextType) {
 		t.AllowedNotReadyNodes = t.CloudConfig.NumNodes / 100
 	}
 
+	// Let the pod construction helpers target the right node OS.
+	if strings.EqualFold(t.NodeOSDistro, "windows") {
+		e2epod.DefaultNodeOS = "windows"
+	}
+
+	// The image package initializes itself from the environment before flags
+	// are parsed, so a repo list given by flag is applied here.
+	if t.ImageRepoList != "" {
+		if err := imageutils.ReloadRegistryList(t.ImageRepoList); err != nil {
+			klog.Errorf("Failed to load image repo list from %q: %v", t.ImageRepoList, err)
+			os.Exit(1)
+		}
+	}
+
 	klog.Infof("Tolerating taints %q when considering if nodes are ready", TestContext.NonblockingTaints)
 
 	// Make sure that all test runs have a valid TestContext.CloudConfig.Provider.
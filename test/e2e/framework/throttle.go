@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// throttleTrackingRateLimiter is a token bucket rate limiter which also
+// accumulates the time requests spent waiting for a token, giving visibility
+// into client-side throttling that otherwise only shows up as slow specs.
+type throttleTrackingRateLimiter struct {
+	flowcontrol.RateLimiter
+
+	lock      sync.Mutex
+	throttled time.Duration
+}
+
+func newThrottleTrackingRateLimiter(qps float32, burst int) *throttleTrackingRateLimiter {
+	return &throttleTrackingRateLimiter{
+		RateLimiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+	}
+}
+
+func (r *throttleTrackingRateLimiter) Accept() {
+	start := time.Now()
+	r.RateLimiter.Accept()
+	r.add(time.Since(start))
+}
+
+func (r *throttleTrackingRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := r.RateLimiter.Wait(ctx)
+	r.add(time.Since(start))
+	return err
+}
+
+func (r *throttleTrackingRateLimiter) add(d time.Duration) {
+	// Requests that get a token right away still take microseconds; only
+	// count waits long enough to indicate actual throttling.
+	if d < time.Millisecond {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.throttled += d
+}
+
+// throttledTime returns the total time requests spent waiting for a token.
+func (r *throttleTrackingRateLimiter) throttledTime() time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.throttled
+}
@@ -41,7 +41,9 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -51,6 +53,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	restclient "k8s.io/client-go/rest"
@@ -133,6 +136,10 @@ const (
 	// SnapshotCreateTimeout is how long for snapshot to create snapshotContent.
 	SnapshotCreateTimeout = 5 * time.Minute
 
+	// SnapshotDeleteTimeout is how long for snapshot deletion to propagate to
+	// the bound snapshotContent.
+	SnapshotDeleteTimeout = 5 * time.Minute
+
 	// Minimal number of nodes for the cluster to be considered large.
 	largeClusterThreshold = 100
 
@@ -312,6 +319,10 @@ func CreateTestingNS(baseName string, c clientset.Interface, labels map[string]s
 	}
 	labels["e2e-run"] = string(RunID)
 
+	if TestContext.ReuseNamespace {
+		return reuseTestingNS(baseName, c, labels)
+	}
+
 	// We don't use ObjectMeta.GenerateName feature, as in case of API call
 	// failure we don't know whether the namespace was created and what is its
 	// name.
@@ -356,6 +367,57 @@ func CreateTestingNS(baseName string, c clientset.Interface, labels map[string]s
 	return got, nil
 }
 
+// reusedByAnnotation marks a persistent developer namespace with the base
+// name of the suite using it, so reuse across different suites is detected.
+const reusedByAnnotation = "e2e.kubernetes.io/reused-by"
+
+// reuseTestingNS returns the deterministic namespace for baseName, creating
+// it on first use. It backs the --reuse-namespace developer mode, which keeps
+// one namespace alive across runs to iterate on a single spec.
+func reuseTestingNS(baseName string, c clientset.Interface, labels map[string]string) (*v1.Namespace, error) {
+	name := baseName + "-reuse"
+	ns, err := c.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil {
+		if reusedBy := ns.Annotations[reusedByAnnotation]; reusedBy != baseName {
+			Logf("Warning: reused namespace %q was last used by %q, objects left behind may interfere with this run", name, reusedBy)
+		}
+		if ns.Annotations == nil {
+			ns.Annotations = map[string]string{}
+		}
+		ns.Annotations[reusedByAnnotation] = baseName
+		for k, v := range labels {
+			if ns.Labels == nil {
+				ns.Labels = map[string]string{}
+			}
+			ns.Labels[k] = v
+		}
+		if ns, err = c.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{}); err != nil {
+			return nil, err
+		}
+		return ns, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	namespaceObj := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: map[string]string{reusedByAnnotation: baseName},
+		},
+	}
+	got, err := c.CoreV1().Namespaces().Create(context.TODO(), namespaceObj, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if TestContext.VerifyServiceAccount {
+		if err := WaitForDefaultServiceAccountInNamespace(c, got.Name); err != nil {
+			return got, err
+		}
+	}
+	return got, nil
+}
+
 // CheckTestingNSDeletedExcept checks whether all e2e based existing namespaces are in the Terminating state
 // and waits until they are finally deleted. It ignores namespace skip.
 func CheckTestingNSDeletedExcept(c clientset.Interface, skip string) error {
@@ -395,7 +457,7 @@ func CheckTestingNSDeletedExcept(c clientset.Interface, skip string) error {
 	return fmt.Errorf("Waiting for terminating namespaces to be deleted timed out")
 }
 
-//WaitForServiceEndpointsNum waits until the amount of endpoints that implement service to expectNum.
+// WaitForServiceEndpointsNum waits until the amount of endpoints that implement service to expectNum.
 func WaitForServiceEndpointsNum(c clientset.Interface, namespace, serviceName string, expectNum int, interval, timeout time.Duration) error {
 	return wait.Poll(interval, timeout, func() (bool, error) {
 		Logf("Waiting for amount of service:%s endpoints to be %d", serviceName, expectNum)
@@ -413,6 +475,50 @@ func WaitForServiceEndpointsNum(c clientset.Interface, namespace, serviceName st
 	})
 }
 
+// WaitForObjectCount waits until exactly expectNum objects of the given
+// resource matching the label selector exist in the namespace. It observes
+// the count through a single watch instead of repeated lists, so it works
+// for any resource the dynamic client can reach.
+func WaitForObjectCount(client dynamic.Interface, gvr schema.GroupVersionResource, namespace, selector string, expectNum int, timeout time.Duration) error {
+	Logf("Waiting up to %v for %d %v in namespace %q matching %q", timeout, expectNum, gvr.Resource, namespace, selector)
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), timeout)
+	defer cancel()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = selector
+			return client.Resource(gvr).Namespace(namespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = selector
+			return client.Resource(gvr).Namespace(namespace).Watch(context.TODO(), options)
+		},
+	}
+	// The initial list may already satisfy the expectation, which UntilWithSync
+	// only notices through the precondition.
+	preconditionFunc := func(store cache.Store) (bool, error) {
+		return len(store.List()) == expectNum, nil
+	}
+	names := sets.NewString()
+	_, err := watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, preconditionFunc, func(event watch.Event) (bool, error) {
+		metaObj, err := meta.Accessor(event.Object)
+		if err != nil {
+			return false, err
+		}
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			names.Insert(metaObj.GetName())
+		case watch.Deleted:
+			names.Delete(metaObj.GetName())
+		}
+		Logf("Observed %d/%d %v", names.Len(), expectNum, gvr.Resource)
+		return names.Len() == expectNum, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %v waiting for %d %v in namespace %q matching %q, observed %d", timeout, expectNum, gvr.Resource, namespace, selector, names.Len())
+	}
+	return err
+}
+
 func countEndpointsNum(e *v1.Endpoints) int {
 	num := 0
 	for _, sub := range e.Subsets {
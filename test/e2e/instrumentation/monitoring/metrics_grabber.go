@@ -40,7 +40,7 @@ var _ = instrumentation.SIGDescribe("MetricsGrabber", func() {
 		c = f.ClientSet
 		ec = f.KubemarkExternalClusterClientSet
 		framework.ExpectNoError(err)
-		grabber, err = e2emetrics.NewMetricsGrabber(c, ec, true, true, true, true, true)
+		grabber, err = e2emetrics.NewMetricsGrabber(c, ec, f.ClientConfig(), true, true, true, true, true)
 		framework.ExpectNoError(err)
 	})
 
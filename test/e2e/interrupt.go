@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// interruptCleanupTimeout bounds how long an interrupted run spends waiting
+// for its namespaces to go away before exiting. Deletion is requested for all
+// of them regardless, so a slow terminating namespace does not block the exit
+// indefinitely.
+const interruptCleanupTimeout = 2 * time.Minute
+
+// startInterruptHandler installs a SIGINT/SIGTERM handler which runs the
+// registered cleanup actions and deletes the namespaces created by this run
+// before exiting, so aborted CI runs do not leave test namespaces behind.
+// A second signal exits immediately without further cleanup.
+func startInterruptHandler() {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-ch
+		framework.Logf("Received signal %v: running cleanup actions before exiting", sig)
+		go func() {
+			sig := <-ch
+			framework.Logf("Received second signal %v: exiting immediately", sig)
+			os.Exit(2)
+		}()
+		framework.RunCleanupActions()
+		deleteRunNamespaces()
+		os.Exit(1)
+	}()
+}
+
+// deleteRunNamespaces deletes all namespaces labeled with this run's ID, i.e.
+// the ones the framework created for specs that did not get to clean up.
+func deleteRunNamespaces() {
+	c, err := framework.LoadClientset()
+	if err != nil {
+		framework.Logf("Could not load clientset to clean up namespaces: %v", err)
+		return
+	}
+	nsList, err := c.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: "e2e-run=" + string(framework.RunID)})
+	if err != nil {
+		framework.Logf("Could not list namespaces for run %v: %v", framework.RunID, err)
+		return
+	}
+	var deleted []string
+	for _, ns := range nsList.Items {
+		if err := c.CoreV1().Namespaces().Delete(context.TODO(), ns.Name, metav1.DeleteOptions{}); err != nil {
+			framework.Logf("Could not delete namespace %v: %v", ns.Name, err)
+			continue
+		}
+		deleted = append(deleted, ns.Name)
+	}
+	if len(deleted) == 0 {
+		return
+	}
+	framework.Logf("Waiting for %d namespaces from run %v to vanish", len(deleted), framework.RunID)
+	if err := framework.WaitForNamespacesDeleted(c, deleted, interruptCleanupTimeout); err != nil {
+		framework.Logf("Namespace cleanup did not finish before exiting: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+// Tests for kubectl alpha debug, which adds an ephemeral debug container to a
+// running pod. The EphemeralContainers feature gate must be enabled in the
+// cluster, hence the feature tag.
+var _ = SIGDescribe("Kubectl alpha debug [Feature:EphemeralContainers]", func() {
+	f := framework.NewDefaultFramework("kubectl-debug")
+
+	var ns string
+	ginkgo.BeforeEach(func() {
+		ns = f.Namespace.Name
+	})
+
+	createTargetPod := func(name string, shareProcessNamespace bool) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:    "target",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"sleep", "10000"},
+					},
+				},
+			},
+		}
+		if shareProcessNamespace {
+			pod.Spec.ShareProcessNamespace = &shareProcessNamespace
+		}
+		return f.PodClient().CreateSync(pod)
+	}
+
+	// waitForDebugContainerRunning polls the pod until the named ephemeral
+	// container reports a running state.
+	waitForDebugContainerRunning := func(podName, containerName string) {
+		err := wait.PollImmediate(framework.Poll, 2*time.Minute, func() (bool, error) {
+			pod, err := f.ClientSet.CoreV1().Pods(ns).Get(context.TODO(), podName, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			for _, s := range pod.Status.EphemeralContainerStatuses {
+				if s.Name == containerName && s.State.Running != nil {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		framework.ExpectNoError(err, "debug container %s in pod %s never started", containerName, podName)
+	}
+
+	ginkgo.It("should add an ephemeral debug container to a running pod", func() {
+		pod := createTargetPod("debug-target", false)
+
+		ginkgo.By("adding a debug container with kubectl alpha debug")
+		framework.RunKubectlOrDie(ns, "alpha", "debug", pod.Name, fmt.Sprintf("--namespace=%v", ns),
+			"--image="+imageutils.GetE2EImage(imageutils.BusyBox), "--container=debugger", "--", "sleep", "300")
+
+		ginkgo.By("verifying the debug container was added to the pod spec")
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		var debugContainer *v1.EphemeralContainer
+		for i, ec := range pod.Spec.EphemeralContainers {
+			if ec.Name == "debugger" {
+				debugContainer = &pod.Spec.EphemeralContainers[i]
+			}
+		}
+		if debugContainer == nil {
+			framework.Failf("ephemeral container debugger not found in pod %s: %v", pod.Name, pod.Spec.EphemeralContainers)
+		}
+		framework.ExpectEqual(debugContainer.Command, []string{"sleep", "300"})
+
+		ginkgo.By("verifying the debug container started")
+		waitForDebugContainerRunning(pod.Name, "debugger")
+
+		ginkgo.By("verifying the original containers were left untouched")
+		framework.ExpectEqual(len(pod.Spec.Containers), 1)
+		framework.ExpectEqual(pod.Spec.Containers[0].Name, "target")
+	})
+
+	ginkgo.It("should add a debug container targeting another container's process namespace", func() {
+		pod := createTargetPod("debug-target-pid", true)
+
+		ginkgo.By("adding a debug container targeting the main container")
+		framework.RunKubectlOrDie(ns, "alpha", "debug", pod.Name, fmt.Sprintf("--namespace=%v", ns),
+			"--image="+imageutils.GetE2EImage(imageutils.BusyBox), "--container=debugger", "--target=target", "--", "sleep", "300")
+
+		ginkgo.By("verifying the debug container records its target container")
+		pod, err := f.ClientSet.CoreV1().Pods(ns).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		var debugContainer *v1.EphemeralContainer
+		for i, ec := range pod.Spec.EphemeralContainers {
+			if ec.Name == "debugger" {
+				debugContainer = &pod.Spec.EphemeralContainers[i]
+			}
+		}
+		if debugContainer == nil {
+			framework.Failf("ephemeral container debugger not found in pod %s: %v", pod.Name, pod.Spec.EphemeralContainers)
+		}
+		if debugContainer.TargetContainerName != "target" {
+			framework.Failf("expected debug container to target container %q, got %q", "target", debugContainer.TargetContainerName)
+		}
+
+		ginkgo.By("verifying the debug container can see the target container's processes")
+		waitForDebugContainerRunning(pod.Name, "debugger")
+		psOutput := framework.RunKubectlOrDie(ns, "exec", pod.Name, fmt.Sprintf("--namespace=%v", ns), "-c", "debugger", "--", "ps")
+		if !strings.Contains(psOutput, "sleep 10000") {
+			framework.Failf("expected to find the target container's sleep process in debugger, got: %s", psOutput)
+		}
+	})
+})
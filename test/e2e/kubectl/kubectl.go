@@ -861,6 +861,80 @@ metadata:
 				}
 			}
 		})
+
+		ginkgo.It("should support server-side apply and surface field ownership conflicts", func() {
+			nsFlag := fmt.Sprintf("--namespace=%v", ns)
+			configMapYaml := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ssa-test
+data:
+  key: value1
+`
+			ginkgo.By("creating the ConfigMap with a server-side apply")
+			framework.RunKubectlOrDieInput(ns, configMapYaml, "apply", "--server-side", "--field-manager=e2e-owner", "-f", "-", nsFlag)
+
+			ginkgo.By("applying a conflicting change with a different field manager")
+			modifiedYaml := strings.Replace(configMapYaml, "value1", "value2", 1)
+			_, err := framework.RunKubectlInput(ns, modifiedYaml, "apply", "--server-side", "--field-manager=e2e-rival", "-f", "-", nsFlag)
+			if err == nil {
+				framework.Failf("Expected server-side apply with a rival field manager to be rejected with a conflict")
+			}
+			if !strings.Contains(err.Error(), "conflict") {
+				framework.Failf("Expected a field ownership conflict error, got: %v", err)
+			}
+
+			ginkgo.By("forcing the conflicting change")
+			framework.RunKubectlOrDieInput(ns, modifiedYaml, "apply", "--server-side", "--field-manager=e2e-rival", "--force-conflicts", "-f", "-", nsFlag)
+
+			ginkgo.By("verifying the forced value was persisted")
+			output := framework.RunKubectlOrDie(ns, "get", "configmap", "ssa-test", nsFlag, "-o", "jsonpath={.data.key}")
+			if output != "value2" {
+				framework.Failf("Expected forced server-side apply to set data.key to value2, got %q", output)
+			}
+		})
+
+		ginkgo.It("should prune resources absent from the applied configuration", func() {
+			nsFlag := fmt.Sprintf("--namespace=%v", ns)
+			pruneSelector := "e2e-prune-group=true"
+			configMapYaml := func(names ...string) string {
+				var manifests []string
+				for _, name := range names {
+					manifests = append(manifests, fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  labels:
+    e2e-prune-group: "true"
+data:
+  key: value
+`, name))
+				}
+				return strings.Join(manifests, "\n---\n")
+			}
+
+			ginkgo.By("applying a configuration with two ConfigMaps")
+			framework.RunKubectlOrDieInput(ns, configMapYaml("prune-keep", "prune-victim"), "apply", "--prune", "-l", pruneSelector, "-f", "-", nsFlag)
+
+			ginkgo.By("applying a configuration with only one ConfigMap and pruning")
+			framework.RunKubectlOrDieInput(ns, configMapYaml("prune-keep"), "apply", "--prune", "-l", pruneSelector, "-f", "-", nsFlag)
+
+			ginkgo.By("verifying the removed ConfigMap was pruned")
+			err := wait.PollImmediate(framework.Poll, time.Minute, func() (bool, error) {
+				_, err := c.CoreV1().ConfigMaps(ns).Get(context.TODO(), "prune-victim", metav1.GetOptions{})
+				if apierrors.IsNotFound(err) {
+					return true, nil
+				}
+				return false, err
+			})
+			framework.ExpectNoError(err, "ConfigMap prune-victim was not pruned")
+
+			ginkgo.By("verifying the retained ConfigMap still exists")
+			_, err = c.CoreV1().ConfigMaps(ns).Get(context.TODO(), "prune-keep", metav1.GetOptions{})
+			framework.ExpectNoError(err, "ConfigMap prune-keep should have survived pruning")
+		})
 	})
 
 	ginkgo.Describe("Kubectl diff", func() {
@@ -892,6 +966,63 @@ metadata:
 
 			framework.RunKubectlOrDieInput(ns, deployment, "delete", "-f", "-")
 		})
+
+		ginkgo.It("should exit with a zero code when the live object matches the declared configuration", func() {
+			ginkgo.By("create deployment with httpd image")
+			deployment := commonutils.SubstituteImageName(string(readTestFileOrDie(httpdDeployment3Filename)))
+			framework.RunKubectlOrDieInput(ns, deployment, "create", "-f", "-")
+
+			ginkgo.By("verify diff of an identical configuration exits zero")
+			output, err := framework.RunKubectlInput(ns, deployment, "diff", "-f", "-")
+			framework.ExpectNoError(err, "expected no diff against the live object, got output:\n%s", output)
+
+			ginkgo.By("verify diff of a modified configuration exits one")
+			deployment = strings.Replace(deployment, httpdImage, busyboxImage, 1)
+			_, err = framework.RunKubectlInput(ns, deployment, "diff", "-f", "-")
+			exitErr, ok := err.(*exec.ExitError)
+			if !ok || exitErr.ExitCode() != 1 {
+				framework.Failf("Expected kubectl diff exit code of 1, got: %v", err)
+			}
+
+			framework.RunKubectlOrDieInput(ns, deployment, "delete", "-f", "-")
+		})
+	})
+
+	ginkgo.Describe("Kubectl wait", func() {
+		ginkgo.It("should wait for a Deployment condition and for deletion", func() {
+			ginkgo.By("create deployment with httpd image")
+			deployment := commonutils.SubstituteImageName(string(readTestFileOrDie(httpdDeployment3Filename)))
+			framework.RunKubectlOrDieInput(ns, deployment, "create", "-f", "-")
+
+			ginkgo.By("waiting for the Available condition")
+			output := framework.RunKubectlOrDie(ns, "wait", "--for=condition=Available", "--timeout=5m", "deployment/httpd-deployment")
+			if !strings.Contains(output, "condition met") {
+				framework.Failf("Expected wait to report the condition as met, got: %s", output)
+			}
+
+			ginkgo.By("deleting the deployment without waiting")
+			framework.RunKubectlOrDie(ns, "delete", "deployment/httpd-deployment", "--wait=false")
+
+			ginkgo.By("waiting for the deployment to be deleted")
+			framework.RunKubectlOrDie(ns, "wait", "--for=delete", "--timeout=5m", "deployment/httpd-deployment")
+		})
+
+		ginkgo.It("should exit nonzero when the timeout expires before the condition is met", func() {
+			ginkgo.By("create deployment with httpd image")
+			deployment := commonutils.SubstituteImageName(string(readTestFileOrDie(httpdDeployment3Filename)))
+			framework.RunKubectlOrDieInput(ns, deployment, "create", "-f", "-")
+
+			ginkgo.By("waiting for a condition the deployment will never report")
+			_, err := framework.RunKubectl(ns, "wait", "--for=condition=NeverGoingToHappen", "--timeout=10s", "deployment/httpd-deployment")
+			if err == nil {
+				framework.Failf("Expected wait on an unsatisfiable condition to fail")
+			}
+			if !strings.Contains(err.Error(), "timed out") {
+				framework.Failf("Expected a timeout error from kubectl wait, got: %v", err)
+			}
+
+			framework.RunKubectlOrDieInput(ns, deployment, "delete", "-f", "-")
+		})
 	})
 
 	ginkgo.Describe("Kubectl server-side dry-run", func() {
@@ -907,12 +1038,14 @@ metadata:
 			framework.RunKubectlOrDie(ns, "run", podName, "--image="+httpdImage, "--labels=run="+podName, nsFlag)
 
 			ginkgo.By("replace the image in the pod with server-side dry-run")
-			podJSON := framework.RunKubectlOrDie(ns, "get", "pod", podName, "-o", "json", nsFlag)
-			podJSON = strings.Replace(podJSON, httpdImage, busyboxImage, 1)
-			if !strings.Contains(podJSON, busyboxImage) {
-				framework.Failf("Failed replacing image from %s to %s in:\n%s\n", httpdImage, busyboxImage, podJSON)
-			}
-			framework.RunKubectlOrDieInput(ns, podJSON, "replace", "-f", "-", "--dry-run", "server", nsFlag)
+			tk := e2ekubectl.NewTestKubeconfig(framework.TestContext.CertDir, framework.TestContext.Host, framework.TestContext.KubeConfig, framework.TestContext.KubeContext, framework.TestContext.KubectlPath, ns)
+			var testPod v1.Pod
+			_, err := tk.RunJSON("", &testPod, "get", "pod", podName, nsFlag)
+			framework.ExpectNoError(err)
+			testPod.Spec.Containers[0].Image = busyboxImage
+			podJSON, err := json.Marshal(&testPod)
+			framework.ExpectNoError(err)
+			framework.RunKubectlOrDieInput(ns, string(podJSON), "replace", "-f", "-", "--dry-run", "server", nsFlag)
 
 			ginkgo.By("verifying the pod " + podName + " has the right image " + httpdImage)
 			pod, err := c.CoreV1().Pods(ns).Get(context.TODO(), podName, metav1.GetOptions{})
@@ -1582,14 +1715,17 @@ metadata:
 			}
 
 			ginkgo.By("verifying the pod " + podName + " was created")
-			podJSON := framework.RunKubectlOrDie(ns, "get", "pod", podName, nsFlag, "-o", "json")
-			if !strings.Contains(podJSON, podName) {
-				framework.Failf("Failed to find pod %s in [%s]", podName, podJSON)
-			}
+			tk := e2ekubectl.NewTestKubeconfig(framework.TestContext.CertDir, framework.TestContext.Host, framework.TestContext.KubeConfig, framework.TestContext.KubeContext, framework.TestContext.KubectlPath, ns)
+			var runPod v1.Pod
+			_, err = tk.RunJSON("", &runPod, "get", "pod", podName, nsFlag)
+			framework.ExpectNoError(err)
+			framework.ExpectEqual(runPod.Name, podName)
 
 			ginkgo.By("replace the image in the pod")
-			podJSON = strings.Replace(podJSON, httpdImage, busyboxImage, 1)
-			framework.RunKubectlOrDieInput(ns, podJSON, "replace", "-f", "-", nsFlag)
+			runPod.Spec.Containers[0].Image = busyboxImage
+			podJSON, err := json.Marshal(&runPod)
+			framework.ExpectNoError(err)
+			framework.RunKubectlOrDieInput(ns, string(podJSON), "replace", "-f", "-", nsFlag)
 
 			ginkgo.By("verifying the pod " + podName + " has the right image " + busyboxImage)
 			pod, err := c.CoreV1().Pods(ns).Get(context.TODO(), podName, metav1.GetOptions{})
@@ -39,6 +39,7 @@ import (
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2ekubectl "k8s.io/kubernetes/test/e2e/framework/kubectl"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2eportforward "k8s.io/kubernetes/test/e2e/framework/portforward"
 	e2ewebsocket "k8s.io/kubernetes/test/e2e/framework/websocket"
 	testutils "k8s.io/kubernetes/test/utils"
 	imageutils "k8s.io/kubernetes/test/utils/image"
@@ -519,3 +520,81 @@ func wsWrite(conn *websocket.Conn, channel byte, data []byte) error {
 	err := websocket.Message.Send(conn, frame)
 	return err
 }
+
+func doTestConnectSendDisconnectOverAPI(bindAddress string, f *framework.Framework) {
+	ginkgo.By("Creating the target pod")
+	pod := pfPod("abc", "3", "10", "100", bindAddress)
+	if _, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{}); err != nil {
+		framework.Failf("Couldn't create pod: %v", err)
+	}
+	if err := e2epod.WaitTimeoutForPodReadyInNamespace(f.ClientSet, pod.Name, f.Namespace.Name, framework.PodStartTimeout); err != nil {
+		framework.Failf("Pod did not start running: %v", err)
+	}
+
+	ginkgo.By("Starting a port-forward session through the portforward subresource")
+	config, err := framework.LoadConfig()
+	framework.ExpectNoError(err, "unable to get base config")
+	session, err := e2eportforward.ForwardPortToPod(config, f.ClientSet, f.Namespace.Name, pod.Name, 80)
+	if err != nil {
+		framework.Failf("Couldn't establish port-forward session: %v", err)
+	}
+	defer session.Close()
+
+	ginkgo.By("Dialing the local port")
+	conn, err := net.DialTCP("tcp", nil, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: int(session.LocalPort)})
+	if err != nil {
+		framework.Failf("Couldn't connect to port %d: %v", session.LocalPort, err)
+	}
+	defer func() {
+		ginkgo.By("Closing the connection to the local port")
+		conn.Close()
+	}()
+
+	ginkgo.By("Sending the expected data to the local port")
+	fmt.Fprint(conn, "abc")
+
+	ginkgo.By("Closing the write half of the client's connection")
+	if err = conn.CloseWrite(); err != nil {
+		framework.Failf("Couldn't close the write half of the client's connection: %v", err)
+	}
+
+	ginkgo.By("Reading data from the local port until EOF")
+	fromServer, err := ioutil.ReadAll(conn)
+	if err != nil {
+		framework.Failf("Unexpected error reading data from the server: %v", err)
+	}
+
+	if e, a := strings.Repeat("x", 30), string(fromServer); e != a {
+		framework.Failf("Expected %q from server, got %q", e, a)
+	}
+
+	ginkgo.By("Waiting for the target pod to stop running")
+	if err := WaitForTerminatedContainer(f, pod, "portforwardtester"); err != nil {
+		framework.Failf("Container did not terminate: %v", err)
+	}
+
+	ginkgo.By("Verifying logs")
+	gomega.Eventually(func() (string, error) {
+		return e2epod.GetPodLogs(f.ClientSet, f.Namespace.Name, pod.Name, "portforwardtester")
+	}, postStartWaitTimeout, podCheckInterval).Should(gomega.SatisfyAll(
+		gomega.ContainSubstring("Accepted client connection"),
+		gomega.ContainSubstring("Received expected client data"),
+		gomega.ContainSubstring("Done"),
+	))
+}
+
+var _ = SIGDescribe("Port forwarding via the portforward subresource", func() {
+	f := framework.NewDefaultFramework("port-forwarding-api")
+
+	ginkgo.Describe("With a server listening on 0.0.0.0", func() {
+		ginkgo.It("should support a client that connects, sends DATA, and disconnects", func() {
+			doTestConnectSendDisconnectOverAPI("0.0.0.0", f)
+		})
+	})
+
+	ginkgo.Describe("With a server listening on localhost", func() {
+		ginkgo.It("should support a client that connects, sends DATA, and disconnects", func() {
+			doTestConnectSendDisconnectOverAPI("localhost", f)
+		})
+	})
+})
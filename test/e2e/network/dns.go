@@ -29,6 +29,7 @@ import (
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2eservice "k8s.io/kubernetes/test/e2e/framework/service"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+	imageutils "k8s.io/kubernetes/test/utils/image"
 
 	"github.com/onsi/ginkgo"
 )
@@ -321,6 +322,48 @@ var _ = SIGDescribe("DNS", func() {
 		validateDNSResults(f, pod1, append(wheezyFileNames, jessieFileNames...))
 	})
 
+	ginkgo.It("should set the expected hostname and FQDN inside a pod with hostname and subdomain [LinuxOnly]", func() {
+		// Create a test headless service backing the subdomain.
+		ginkgo.By("Creating a test headless service")
+		testServiceSelector := map[string]string{
+			"dns-test-hostname-fqdn": "true",
+		}
+		serviceName := "dns-test-subdomain"
+		podHostname := "custom-host"
+		headlessService := e2eservice.CreateServiceSpec(serviceName, "", true, testServiceSelector)
+		_, err := f.ClientSet.CoreV1().Services(f.Namespace.Name).Create(context.TODO(), headlessService, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create headless service: %s", serviceName)
+
+		defer func() {
+			ginkgo.By("deleting the test headless service")
+			defer ginkgo.GinkgoRecover()
+			f.ClientSet.CoreV1().Services(f.Namespace.Name).Delete(context.TODO(), headlessService.Name, metav1.DeleteOptions{})
+		}()
+
+		hostFQDN := fmt.Sprintf("%s.%s.%s.svc.%s", podHostname, serviceName, f.Namespace.Name, framework.TestContext.ClusterDNSDomain)
+
+		// The container hostname stays the short name; the FQDN is only
+		// reflected by `hostname -f` through the kubelet-managed /etc/hosts.
+		ginkgo.By("creating a pod that prints its hostname and FQDN")
+		pod := newHostnamePrinterPod("test-pod-hostname-fqdn")
+		pod.ObjectMeta.Labels = testServiceSelector
+		pod.Spec.Hostname = podHostname
+		pod.Spec.Subdomain = serviceName
+
+		f.TestContainerOutput("pod hostname and FQDN", pod, 0, []string{
+			fmt.Sprintf("%s;%s", podHostname, hostFQDN),
+		})
+
+		// Without hostname and subdomain both hostname and FQDN default to
+		// the pod name.
+		ginkgo.By("creating a pod without hostname and subdomain")
+		defaultPod := newHostnamePrinterPod("test-pod-default-hostname")
+
+		f.TestContainerOutput("default pod hostname", defaultPod, 0, []string{
+			fmt.Sprintf("%s;%s", defaultPod.Name, defaultPod.Name),
+		})
+	})
+
 	/*
 		Release: v1.15
 		Testname: DNS, for ExternalName Services
@@ -519,27 +562,14 @@ var _ = SIGDescribe("DNS", func() {
 		framework.ExpectNoError(err, "failed to wait for pod %s to be running", testUtilsPod.Name)
 
 		ginkgo.By("Verifying customized DNS option is configured on pod...")
-		// TODO: Figure out a better way other than checking the actual resolv,conf file.
-		cmd := []string{"cat", "/etc/resolv.conf"}
-		stdout, stderr, err := f.ExecWithOptions(framework.ExecOptions{
-			Command:       cmd,
-			Namespace:     f.Namespace.Name,
-			PodName:       testUtilsPod.Name,
-			ContainerName: "util",
-			CaptureStdout: true,
-			CaptureStderr: true,
-		})
-		framework.ExpectNoError(err, "failed to examine resolv,conf file on pod, stdout: %v, stderr: %v, err: %v", stdout, stderr, err)
-		if !strings.Contains(stdout, "ndots:2") {
-			framework.Failf("customized DNS options not found in resolv.conf, got: %s", stdout)
-		}
+		assertResolvConfContains(f, testUtilsPod.Name, "util", []string{"ndots:2"})
 
 		ginkgo.By("Verifying customized name server and search path are working...")
 		// Do dig on not-exist-dns-name and see if the injected DNS record is returned.
 		// This verifies both:
 		// - Custom search path is appended.
 		// - DNS query is sent to the specified server.
-		cmd = []string{"dig", "+short", "+search", testDNSNameShort}
+		cmd := []string{"dig", "+short", "+search", testDNSNameShort}
 		digFunc := func() (bool, error) {
 			stdout, stderr, err := f.ExecWithOptions(framework.ExecOptions{
 				Command:       cmd,
@@ -566,4 +596,128 @@ var _ = SIGDescribe("DNS", func() {
 		// TODO: Add more test cases for other DNSPolicies.
 	})
 
+	ginkgo.It("should write nameservers, searches and options from dnsConfig into resolv.conf", func() {
+		ginkgo.By("Preparing a test DNS service with injected DNS names...")
+		testInjectedIP := "1.1.1.1"
+		testDNSNameShort := "notexistname"
+		testSearchPathFirst := "first.resolv.conf.local"
+		testSearchPathSecond := "second.resolv.conf.local"
+		testDNSNameFull := fmt.Sprintf("%s.%s", testDNSNameShort, testSearchPathSecond)
+
+		corednsConfig := generateCoreDNSConfigmap(f.Namespace.Name, map[string]string{
+			testDNSNameFull: testInjectedIP,
+		})
+		corednsConfig, err := f.ClientSet.CoreV1().ConfigMaps(f.Namespace.Name).Create(context.TODO(), corednsConfig, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "unable to create test configMap %s", corednsConfig.Name)
+		defer func() {
+			framework.Logf("Deleting configmap %s...", corednsConfig.Name)
+			err := f.ClientSet.CoreV1().ConfigMaps(f.Namespace.Name).Delete(context.TODO(), corednsConfig.Name, metav1.DeleteOptions{})
+			framework.ExpectNoError(err, "Failed to delete configmap %s: %v", corednsConfig.Name)
+		}()
+
+		testServerPod := generateCoreDNSServerPod(corednsConfig)
+		testServerPod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), testServerPod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create pod: %s", testServerPod.Name)
+		defer func() {
+			framework.Logf("Deleting pod %s...", testServerPod.Name)
+			if err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).Delete(context.TODO(), testServerPod.Name, *metav1.NewDeleteOptions(0)); err != nil {
+				framework.Failf("ginkgo.Failed to delete pod %s: %v", testServerPod.Name, err)
+			}
+		}()
+		err = e2epod.WaitForPodNameRunningInNamespace(f.ClientSet, testServerPod.Name, f.Namespace.Name)
+		framework.ExpectNoError(err, "failed to wait for pod %s to be running", testServerPod.Name)
+
+		testServerPod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Get(context.TODO(), testServerPod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "failed to get pod %v", testServerPod.Name)
+		testServerIP := testServerPod.Status.PodIP
+		framework.Logf("testServerIP is %s", testServerIP)
+
+		ginkgo.By("Creating a pod with dnsPolicy=None and a dnsConfig with nameservers, searches and options...")
+		testUtilsPod := generateDNSUtilsPod()
+		testUtilsPod.Spec.DNSPolicy = v1.DNSNone
+		testNdotsValue := "2"
+		testAttemptsValue := "3"
+		testUtilsPod.Spec.DNSConfig = &v1.PodDNSConfig{
+			Nameservers: []string{testServerIP},
+			Searches:    []string{testSearchPathFirst, testSearchPathSecond},
+			Options: []v1.PodDNSConfigOption{
+				{
+					Name:  "ndots",
+					Value: &testNdotsValue,
+				},
+				{
+					Name:  "attempts",
+					Value: &testAttemptsValue,
+				},
+			},
+		}
+		testUtilsPod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), testUtilsPod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create pod: %s", testUtilsPod.Name)
+		defer func() {
+			framework.Logf("Deleting pod %s...", testUtilsPod.Name)
+			if err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).Delete(context.TODO(), testUtilsPod.Name, *metav1.NewDeleteOptions(0)); err != nil {
+				framework.Failf("ginkgo.Failed to delete pod %s: %v", testUtilsPod.Name, err)
+			}
+		}()
+		err = e2epod.WaitForPodNameRunningInNamespace(f.ClientSet, testUtilsPod.Name, f.Namespace.Name)
+		framework.ExpectNoError(err, "failed to wait for pod %s to be running", testUtilsPod.Name)
+
+		ginkgo.By("Verifying every dnsConfig entry is present in resolv.conf...")
+		assertResolvConfContains(f, testUtilsPod.Name, "util", []string{
+			"nameserver " + testServerIP,
+			testSearchPathFirst,
+			testSearchPathSecond,
+			"ndots:2",
+			"attempts:3",
+		})
+
+		ginkgo.By("Verifying the search path list is traversed when resolving a short name...")
+		// The injected record only exists under the second search path, so a
+		// successful lookup proves both search entries were written in order
+		// and the custom name server answered the query.
+		cmd := []string{"dig", "+short", "+search", testDNSNameShort}
+		digFunc := func() (bool, error) {
+			stdout, stderr, err := f.ExecWithOptions(framework.ExecOptions{
+				Command:       cmd,
+				Namespace:     f.Namespace.Name,
+				PodName:       testUtilsPod.Name,
+				ContainerName: "util",
+				CaptureStdout: true,
+				CaptureStderr: true,
+			})
+			if err != nil {
+				framework.Logf("ginkgo.Failed to execute dig command, stdout:%v, stderr: %v, err: %v", stdout, stderr, err)
+				return false, nil
+			}
+			res := strings.Split(stdout, "\n")
+			if len(res) != 1 || res[0] != testInjectedIP {
+				framework.Logf("Expect command `%v` to return %s, got: %v", cmd, testInjectedIP, res)
+				return false, nil
+			}
+			return true, nil
+		}
+		err = wait.PollImmediate(5*time.Second, 3*time.Minute, digFunc)
+		framework.ExpectNoError(err, "failed to verify name resolution through the configured search path")
+	})
+
 })
+
+// newHostnamePrinterPod returns a pod that prints its short hostname and its
+// FQDN, separated by a semicolon, and then exits.
+func newHostnamePrinterPod(name string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:    "hostname-printer",
+					Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+					Command: []string{"sh", "-c", "echo $(hostname)';'$(hostname -f)"},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+}
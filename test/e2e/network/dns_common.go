@@ -645,3 +645,25 @@ func generateDNSUtilsPod() *v1.Pod {
 		},
 	}
 }
+
+// assertResolvConfContains reads /etc/resolv.conf from the given container of
+// the pod and fails the test unless every expected entry is present. Entries
+// are matched as substrings, so both whole lines ("nameserver 1.1.1.1") and
+// individual tokens ("ndots:2") can be asserted.
+func assertResolvConfContains(f *framework.Framework, podName, containerName string, expectedEntries []string) {
+	cmd := []string{"cat", "/etc/resolv.conf"}
+	stdout, stderr, err := f.ExecWithOptions(framework.ExecOptions{
+		Command:       cmd,
+		Namespace:     f.Namespace.Name,
+		PodName:       podName,
+		ContainerName: containerName,
+		CaptureStdout: true,
+		CaptureStderr: true,
+	})
+	framework.ExpectNoError(err, "failed to examine resolv.conf file on pod, stdout: %v, stderr: %v, err: %v", stdout, stderr, err)
+	for _, expected := range expectedEntries {
+		if !strings.Contains(stdout, expected) {
+			framework.Failf("expected resolv.conf of pod %s to contain %q, got: %s", podName, expected, stdout)
+		}
+	}
+}
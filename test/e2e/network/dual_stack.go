@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo"
@@ -36,7 +37,6 @@ import (
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2eservice "k8s.io/kubernetes/test/e2e/framework/service"
 	imageutils "k8s.io/kubernetes/test/utils/image"
-	netutils "k8s.io/utils/net"
 )
 
 // Tests for ipv6 dual stack feature
@@ -340,6 +340,73 @@ var _ = SIGDescribe("[Feature:IPv6DualStackAlphaFeature] [LinuxOnly]", func() {
 			framework.Failf("Get endpoints for service %s/%s failed (%s)", svc.Namespace, svc.Name, err)
 		}
 	})
+
+	ginkgo.It("should be able to reach a service via its cluster IP for each IP family [Feature:IPv6DualStackAlphaFeature:Phase2]", func() {
+		labels := map[string]string{"dualstack-svc-conn": "true"}
+
+		ginkgo.By("creating a dual stack server pod")
+		serverPod := podClient.CreateSync(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "dualstack-conn-server",
+				Labels: labels,
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "agnhost",
+						Image: imageutils.GetE2EImage(imageutils.Agnhost),
+						Args:  []string{"netexec", "--http-port=8080"},
+						Ports: []v1.ContainerPort{{ContainerPort: 8080}},
+					},
+				},
+			},
+		})
+		framework.ExpectNoError(e2epod.VerifyPodIPFamilies(serverPod, []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol}))
+
+		ginkgo.By("creating a client pod")
+		clientPod := podClient.CreateSync(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "dualstack-conn-client",
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:    "client",
+						Image:   imageutils.GetE2EImage(imageutils.Agnhost),
+						Command: []string{"sleep", "3600"},
+					},
+				},
+			},
+		})
+
+		for _, family := range []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol} {
+			family := family
+			name := "dualstack-conn-svc-" + strings.ToLower(string(family))
+			ginkgo.By(fmt.Sprintf("creating a %s service in front of the server pod", family))
+			svc := createService(name, f.Namespace.Name, labels, &family)
+			svc.Spec.Type = v1.ServiceTypeClusterIP
+			svc.Spec.Ports = []v1.ServicePort{{Name: "http", Port: 8080, Protocol: v1.ProtocolTCP}}
+			svc, err := cs.CoreV1().Services(f.Namespace.Name).Create(context.TODO(), svc, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+			framework.ExpectNoError(e2eservice.VerifyServiceIPFamily(svc, family))
+
+			ginkgo.By(fmt.Sprintf("waiting for %s family endpoints", family))
+			framework.ExpectNoError(wait.PollImmediate(framework.Poll, e2eservice.ServiceEndpointsTimeout, func() (bool, error) {
+				endpoints, err := cs.CoreV1().Endpoints(f.Namespace.Name).Get(context.TODO(), svc.Name, metav1.GetOptions{})
+				if err != nil {
+					return false, nil
+				}
+				return e2eservice.VerifyEndpointsIPFamily(endpoints, family) == nil, nil
+			}))
+
+			ginkgo.By(fmt.Sprintf("connecting to the %s cluster IP from the client pod", family))
+			framework.ExpectNoError(wait.PollImmediate(framework.Poll, e2eservice.ServiceEndpointsTimeout, func() (bool, error) {
+				cmd := checkNetworkConnectivity(svc.Spec.ClusterIP, "8080", 10)
+				_, _, err := f.ExecCommandInContainerWithFullOutput(clientPod.Name, "client", cmd...)
+				return err == nil, nil
+			}), "failed to reach %s service via cluster IP %s", family, svc.Spec.ClusterIP)
+		}
+	})
 })
 
 func validateNumOfServicePorts(svc *v1.Service, expectedNumOfPorts int) {
@@ -349,29 +416,14 @@ func validateNumOfServicePorts(svc *v1.Service, expectedNumOfPorts int) {
 }
 
 func validateServiceAndClusterIPFamily(svc *v1.Service, expectedIPFamily v1.IPFamily) {
-	if svc.Spec.IPFamily == nil {
-		framework.Failf("service ip family nil for service %s/%s", svc.Namespace, svc.Name)
-	}
-	if *svc.Spec.IPFamily != expectedIPFamily {
-		framework.Failf("ip family mismatch for service: %s/%s, expected: %s, actual: %s", svc.Namespace, svc.Name, expectedIPFamily, *svc.Spec.IPFamily)
-	}
-
-	isIPv6ClusterIP := netutils.IsIPv6String(svc.Spec.ClusterIP)
-	if (expectedIPFamily == v1.IPv4Protocol && isIPv6ClusterIP) || (expectedIPFamily == v1.IPv6Protocol && !isIPv6ClusterIP) {
-		framework.Failf("got unexpected service ip %s, should belong to %s ip family", svc.Spec.ClusterIP, expectedIPFamily)
+	if err := e2eservice.VerifyServiceIPFamily(svc, expectedIPFamily); err != nil {
+		framework.Failf("%v", err)
 	}
 }
 
 func validateEndpointsBelongToIPFamily(svc *v1.Service, endpoint *v1.Endpoints, expectedIPFamily v1.IPFamily) {
-	if len(endpoint.Subsets) == 0 {
-		framework.Failf("Endpoint has no subsets, cannot determine service ip family matches endpoints ip family for service %s/%s", svc.Namespace, svc.Name)
-	}
-	for _, ss := range endpoint.Subsets {
-		for _, e := range ss.Addresses {
-			if (expectedIPFamily == v1.IPv6Protocol && isIPv4(e.IP)) || (expectedIPFamily == v1.IPv4Protocol && netutils.IsIPv6String(e.IP)) {
-				framework.Failf("service endpoint %s doesn't belong to %s ip family", e.IP, expectedIPFamily)
-			}
-		}
+	if err := e2eservice.VerifyEndpointsIPFamily(endpoint, expectedIPFamily); err != nil {
+		framework.Failf("service %s/%s: %v", svc.Namespace, svc.Name, err)
 	}
 }
 
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	imageutils "k8s.io/kubernetes/test/utils/image"
 
 	"github.com/onsi/ginkgo"
@@ -299,6 +300,81 @@ var _ = SIGDescribe("EndpointSlice", func() {
 		deleteEndpointSlices(cs, f.Namespace.Name, svc2)
 		expectEndpointsAndSlices(cs, f.Namespace.Name, svc2, []*v1.Pod{pod1, pod2}, 2, 2, true)
 	})
+
+	ginkgo.It("should route traffic to a Service without a selector backed by a manual EndpointSlice", func() {
+		ns := f.Namespace.Name
+
+		ginkgo.By("creating a backend pod")
+		backend := podClient.CreateSync(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "slice-backend-pod",
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:  "agnhost",
+						Image: imageutils.GetE2EImage(imageutils.Agnhost),
+						Args:  []string{"netexec"},
+						Ports: []v1.ContainerPort{{ContainerPort: 8080}},
+					},
+				},
+			},
+		})
+
+		ginkgo.By("creating a Service without a selector")
+		svc := createServiceReportErr(cs, ns, &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "slice-manual-svc",
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+					Protocol:   v1.ProtocolTCP,
+				}},
+			},
+		})
+
+		ginkgo.By("verifying no EndpointSlice is created for the selectorless Service")
+		sliceList, err := cs.DiscoveryV1beta1().EndpointSlices(ns).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: discoveryv1beta1.LabelServiceName + "=" + svc.Name,
+		})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(sliceList.Items), 0)
+
+		ginkgo.By("creating an EndpointSlice for the Service by hand")
+		portName := ""
+		protocol := v1.ProtocolTCP
+		port := int32(8080)
+		ready := true
+		_, err = cs.DiscoveryV1beta1().EndpointSlices(ns).Create(context.TODO(), &discoveryv1beta1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   svc.Name + "-manual",
+				Labels: map[string]string{discoveryv1beta1.LabelServiceName: svc.Name},
+			},
+			AddressType: discoveryv1beta1.AddressTypeIPv4,
+			Ports: []discoveryv1beta1.EndpointPort{{
+				Name:     &portName,
+				Port:     &port,
+				Protocol: &protocol,
+			}},
+			Endpoints: []discoveryv1beta1.Endpoint{{
+				Addresses:  []string{backend.Status.PodIP},
+				Conditions: discoveryv1beta1.EndpointConditions{Ready: &ready},
+			}},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("connecting to the Service from a client pod")
+		execPod := e2epod.CreateExecPodOrFail(cs, ns, "slice-client-pod", nil)
+		target := fmt.Sprintf("http://%s:%d/hostname", svc.Spec.ClusterIP, svc.Spec.Ports[0].Port)
+		checkReachabilityFromPod(true, 2*time.Minute, ns, execPod.Name, target)
+
+		ginkgo.By("removing the EndpointSlice and verifying the Service stops routing")
+		err = cs.DiscoveryV1beta1().EndpointSlices(ns).Delete(context.TODO(), svc.Name+"-manual", metav1.DeleteOptions{})
+		framework.ExpectNoError(err)
+		checkReachabilityFromPod(false, 2*time.Minute, ns, execPod.Name, target)
+	})
 })
 
 // expectEndpointsAndSlices verifies that Endpoints and EndpointSlices exist for
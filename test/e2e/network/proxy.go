@@ -33,8 +33,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/net"
 	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2erc "k8s.io/kubernetes/test/e2e/framework/rc"
 	testutils "k8s.io/kubernetes/test/utils"
 	imageutils "k8s.io/kubernetes/test/utils/image"
@@ -258,6 +260,95 @@ var _ = SIGDescribe("Proxy", func() {
 				framework.Failf(strings.Join(errs, "\n"))
 			}
 		})
+
+		ginkgo.It("should pass the path and query through pods/proxy and services/proxy", func() {
+			ns := f.Namespace.Name
+			labels := map[string]string{"proxy-echo-target": "true"}
+
+			ginkgo.By("Creating an agnhost netexec pod and a service in front of it")
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "proxy-echo-pod",
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "agnhost",
+							Image: imageutils.GetE2EImage(imageutils.Agnhost),
+							Args:  []string{"netexec"},
+							Ports: []v1.ContainerPort{{ContainerPort: 8080}},
+						},
+					},
+				},
+			}
+			_, err := f.ClientSet.CoreV1().Pods(ns).Create(context.TODO(), pod, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+			service, err := f.ClientSet.CoreV1().Services(ns).Create(context.TODO(), &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "proxy-echo-service"},
+				Spec: v1.ServiceSpec{
+					Selector: labels,
+					Ports: []v1.ServicePort{
+						{
+							Name:       "http",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+			framework.ExpectNoError(e2epod.WaitForPodNameRunningInNamespace(f.ClientSet, pod.Name, ns))
+			framework.ExpectNoError(waitForEndpoint(f.ClientSet, ns, service.Name))
+
+			// The echo handler of netexec writes back the msg query
+			// parameter, so a matching body proves both the path and the
+			// query string survived the proxy.
+			ginkgo.By("Proxying to the pod with a path and query string")
+			body, err := f.ClientSet.CoreV1().RESTClient().Get().
+				AbsPath(prefix, "namespaces", ns, "pods", pod.Name+":8080", "proxy", "echo").
+				Param("msg", "pods-proxy-passthrough").
+				Do(context.TODO()).Raw()
+			framework.ExpectNoError(err)
+			framework.ExpectEqual(string(body), "pods-proxy-passthrough")
+
+			ginkgo.By("Proxying to the service with a path and query string")
+			body, err = f.ClientSet.CoreV1().RESTClient().Get().
+				AbsPath(prefix, "namespaces", ns, "services", service.Name+":http", "proxy", "echo").
+				Param("msg", "services-proxy-passthrough").
+				Do(context.TODO()).Raw()
+			framework.ExpectNoError(err)
+			framework.ExpectEqual(string(body), "services-proxy-passthrough")
+		})
+
+		ginkgo.It("should reject nodes/proxy requests from an impersonated unprivileged user", func() {
+			node, err := e2enode.GetRandomReadySchedulableNode(f.ClientSet)
+			framework.ExpectNoError(err)
+
+			ginkgo.By("Proxying to the kubelet healthz endpoint as the privileged test user")
+			_, err = f.ClientSet.CoreV1().RESTClient().Get().
+				AbsPath(prefix, "nodes", node.Name, "proxy", "healthz").
+				Do(context.TODO()).Raw()
+			framework.ExpectNoError(err)
+
+			ginkgo.By("Building a client that impersonates a user without nodes/proxy permissions")
+			config, err := framework.LoadConfig()
+			framework.ExpectNoError(err)
+			config.Impersonate = restclient.ImpersonationConfig{
+				UserName: "e2e-unprivileged-proxy-user",
+				Groups:   []string{"system:authenticated"},
+			}
+			impersonatedClient, err := clientset.NewForConfig(config)
+			framework.ExpectNoError(err)
+
+			ginkgo.By("Proxying to the kubelet healthz endpoint as the impersonated user")
+			_, err = impersonatedClient.CoreV1().RESTClient().Get().
+				AbsPath(prefix, "nodes", node.Name, "proxy", "healthz").
+				Do(context.TODO()).Raw()
+			if !apierrors.IsForbidden(err) {
+				framework.Failf("expected a forbidden error for the unprivileged user, got %v", err)
+			}
+		})
 	})
 })
 
@@ -43,6 +43,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 	cloudprovider "k8s.io/cloud-provider"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2edeployment "k8s.io/kubernetes/test/e2e/framework/deployment"
@@ -3018,6 +3019,74 @@ var _ = SIGDescribe("Services", func() {
 			}
 		}
 	})
+
+	ginkgo.It("should update the status of a Service through the services/status subresource", func() {
+		ns := f.Namespace.Name
+		svcClient := f.ClientSet.CoreV1().Services(ns)
+		testSvcName := "test-service-status"
+
+		ginkgo.By("creating a Service")
+		_, err := svcClient.Create(context.TODO(), &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testSvcName,
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Name:       "http",
+					Protocol:   v1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(80),
+				}},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "failed to create Service")
+
+		ginkgo.By("setting status.loadBalancer.ingress via the status subresource")
+		ingress := v1.LoadBalancerIngress{IP: "203.0.113.1"}
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			svc, err := svcClient.Get(context.TODO(), testSvcName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			svc.Status.LoadBalancer = v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{ingress}}
+			_, err = svcClient.UpdateStatus(context.TODO(), svc, metav1.UpdateOptions{})
+			return err
+		})
+		framework.ExpectNoError(err, "failed to update status of Service %s", testSvcName)
+
+		ginkgo.By("verifying the status update is visible on a fresh get")
+		svc, err := svcClient.Get(context.TODO(), testSvcName, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(svc.Status.LoadBalancer.Ingress), 1)
+		framework.ExpectEqual(svc.Status.LoadBalancer.Ingress[0].IP, "203.0.113.1")
+
+		ginkgo.By("verifying a spec update does not clobber the status")
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			svc, err := svcClient.Get(context.TODO(), testSvcName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			svc.Labels = map[string]string{"test-service-status": "updated"}
+			_, err = svcClient.Update(context.TODO(), svc, metav1.UpdateOptions{})
+			return err
+		})
+		framework.ExpectNoError(err)
+		svc, err = svcClient.Get(context.TODO(), testSvcName, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(svc.Status.LoadBalancer.Ingress), 1, "spec update removed the load balancer status")
+
+		ginkgo.By("patching the status subresource with a new ingress entry")
+		statusPatch := []byte(`{"status":{"loadBalancer":{"ingress":[{"ip":"203.0.113.1"},{"hostname":"e2e.example.com"}]}}}`)
+		svc, err = svcClient.Patch(context.TODO(), testSvcName, types.MergePatchType, statusPatch, metav1.PatchOptions{}, "status")
+		framework.ExpectNoError(err, "failed to patch status of Service %s", testSvcName)
+		framework.ExpectEqual(len(svc.Status.LoadBalancer.Ingress), 2)
+		framework.ExpectEqual(svc.Status.LoadBalancer.Ingress[1].Hostname, "e2e.example.com")
+
+		ginkgo.By("clearing the load balancer status again")
+		svc, err = svcClient.Patch(context.TODO(), testSvcName, types.MergePatchType, []byte(`{"status":{"loadBalancer":null}}`), metav1.PatchOptions{}, "status")
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(svc.Status.LoadBalancer.Ingress), 0)
+	})
 })
 
 var _ = SIGDescribe("ESIPP [Slow]", func() {
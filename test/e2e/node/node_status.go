@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+
+	"github.com/onsi/ginkgo"
+)
+
+// testNodeConditionType is a condition type owned by this test. The kubelet
+// only manages its own condition types, so this one must survive heartbeats.
+const testNodeConditionType v1.NodeConditionType = "E2ENodeStatusTest"
+
+var _ = SIGDescribe("NodeStatus", func() {
+	f := framework.NewDefaultFramework("node-status")
+	var nodeName string
+
+	ginkgo.BeforeEach(func() {
+		node, err := e2enode.GetRandomReadySchedulableNode(f.ClientSet)
+		framework.ExpectNoError(err)
+		nodeName = node.Name
+	})
+
+	ginkgo.It("should allow patching node labels and annotations", func() {
+		labelKey := "e2e-node-status-" + f.UniqueName
+		annotationKey := "e2e-node-status-annotation"
+
+		ginkgo.By(fmt.Sprintf("Patching a label and an annotation onto node %v", nodeName))
+		patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:"testvalue"},"annotations":{%q:"testvalue"}}}`, labelKey, annotationKey))
+		node, err := f.ClientSet.CoreV1().Nodes().Patch(context.TODO(), nodeName, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(node.Labels[labelKey], "testvalue")
+		framework.ExpectEqual(node.Annotations[annotationKey], "testvalue")
+
+		ginkgo.By("Removing the label and the annotation again")
+		patch = []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:null},"annotations":{%q:null}}}`, labelKey, annotationKey))
+		node, err = f.ClientSet.CoreV1().Nodes().Patch(context.TODO(), nodeName, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		framework.ExpectNoError(err)
+		_, found := node.Labels[labelKey]
+		framework.ExpectEqual(found, false)
+		_, found = node.Annotations[annotationKey]
+		framework.ExpectEqual(found, false)
+	})
+
+	ginkgo.It("should keep a custom condition on the nodes/status subresource across kubelet heartbeats", func() {
+		nodeClient := f.ClientSet.CoreV1().Nodes()
+
+		ginkgo.By(fmt.Sprintf("Patching a custom condition onto the status of node %v", nodeName))
+		condition := v1.NodeCondition{
+			Type:               testNodeConditionType,
+			Status:             v1.ConditionTrue,
+			Reason:             "E2ETesting",
+			Message:            "Set by the e2e suite",
+			LastTransitionTime: metav1.Now(),
+			LastHeartbeatTime:  metav1.Now(),
+		}
+		patch, err := json.Marshal(map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []v1.NodeCondition{condition},
+			},
+		})
+		framework.ExpectNoError(err)
+		node, err := nodeClient.Patch(context.TODO(), nodeName, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status")
+		framework.ExpectNoError(err)
+		framework.ExpectNotEqual(findNodeCondition(node, testNodeConditionType), nil)
+
+		defer func() {
+			// Remove the condition again so repeated runs start clean.
+			deletePatch := []byte(fmt.Sprintf(`{"status":{"conditions":[{"type":%q,"$patch":"delete"}]}}`, testNodeConditionType))
+			_, err := nodeClient.Patch(context.TODO(), nodeName, types.StrategicMergePatchType, deletePatch, metav1.PatchOptions{}, "status")
+			framework.ExpectNoError(err)
+		}()
+
+		ginkgo.By("Waiting for the kubelet to heartbeat the node status")
+		readyHeartbeat := findNodeCondition(node, v1.NodeReady).LastHeartbeatTime
+		err = wait.PollImmediate(framework.Poll, 5*time.Minute, func() (bool, error) {
+			node, err = nodeClient.Get(context.TODO(), nodeName, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			ready := findNodeCondition(node, v1.NodeReady)
+			if ready == nil {
+				return false, fmt.Errorf("node %v lost its Ready condition", nodeName)
+			}
+			return ready.LastHeartbeatTime.After(readyHeartbeat.Time), nil
+		})
+		framework.ExpectNoError(err, "timed out waiting for a fresh kubelet heartbeat on node %v", nodeName)
+
+		ginkgo.By("Verifying the custom condition survived the heartbeat")
+		framework.ExpectNotEqual(findNodeCondition(node, testNodeConditionType), nil)
+	})
+
+	ginkgo.It("should list nodes with a spec.unschedulable field selector", func() {
+		nodeClient := f.ClientSet.CoreV1().Nodes()
+
+		allNodes, err := nodeClient.List(context.TODO(), metav1.ListOptions{})
+		framework.ExpectNoError(err)
+		schedulable := 0
+		for _, node := range allNodes.Items {
+			if !node.Spec.Unschedulable {
+				schedulable++
+			}
+		}
+
+		ginkgo.By("Listing nodes with fieldSelector spec.unschedulable=false")
+		selected, err := nodeClient.List(context.TODO(), metav1.ListOptions{FieldSelector: "spec.unschedulable=false"})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(selected.Items), schedulable)
+
+		ginkgo.By("Listing nodes with fieldSelector spec.unschedulable=true")
+		selected, err = nodeClient.List(context.TODO(), metav1.ListOptions{FieldSelector: "spec.unschedulable=true"})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(selected.Items), len(allNodes.Items)-schedulable)
+	})
+})
+
+// findNodeCondition returns the condition of the given type from the node's
+// status, or nil if it is not present.
+func findNodeCondition(node *v1.Node, conditionType v1.NodeConditionType) *v1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == conditionType {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
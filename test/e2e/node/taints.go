@@ -408,6 +408,71 @@ var _ = SIGDescribe("NoExecuteTaintManager Multiple Pods [Serial]", func() {
 		}
 	})
 
+	// 1. Run an untolerated pod and a pod with a finite toleration on the same node
+	// 2. Taint the node with a no-execute taint
+	// 3. See if the untolerated pod is evicted immediately while the tolerating pod
+	//    survives its toleration window and is evicted only after it expires
+	ginkgo.It("evicts the untolerated pod first and the pod with tolerationSeconds after the window", func() {
+		podGroup := "taint-eviction-c"
+		observedDeletions := make(chan string, 100)
+		stopCh := make(chan struct{})
+		createTestController(cs, observedDeletions, stopCh, podGroup, ns)
+
+		pod1 := createPodForTaintsTest(false, 0, podGroup+"1", podGroup, ns)
+		pod2 := createPodForTaintsTest(true, kubeletPodDeletionDelaySeconds+2*additionalWaitPerDeleteSeconds, podGroup+"2", podGroup, ns)
+
+		ginkgo.By("Starting pods...")
+		nodeName, err := testutils.RunPodAndGetNodeName(cs, pod1, 2*time.Minute)
+		framework.ExpectNoError(err)
+		node, err := cs.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		nodeHostNameLabel, ok := node.GetObjectMeta().GetLabels()["kubernetes.io/hostname"]
+		if !ok {
+			framework.Failf("error getting kubernetes.io/hostname label on node %s", nodeName)
+		}
+		framework.Logf("Pod1 is running on %v. Tainting Node", nodeName)
+		// ensure pod2 lands on the same node as pod1
+		pod2.Spec.NodeSelector = map[string]string{"kubernetes.io/hostname": nodeHostNameLabel}
+		_, err = testutils.RunPodAndGetNodeName(cs, pod2, 2*time.Minute)
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(e2epod.WaitForPodRunningInNamespace(cs, pod1))
+		framework.ExpectNoError(e2epod.WaitForPodRunningInNamespace(cs, pod2))
+		framework.Logf("Pod2 is running on %v. Tainting Node", nodeName)
+
+		ginkgo.By("Trying to apply a taint on the Node")
+		testTaint := getTestTaint()
+		e2enode.AddOrUpdateTaintOnNode(cs, nodeName, testTaint)
+		framework.ExpectNodeHasTaint(cs, nodeName, &testTaint)
+		defer e2enode.RemoveTaintOffNode(cs, nodeName, testTaint)
+
+		ginkgo.By("Waiting for the untolerated Pod to be deleted")
+		timeoutChannel := time.NewTimer(time.Duration(kubeletPodDeletionDelaySeconds+additionalWaitPerDeleteSeconds) * time.Second).C
+		select {
+		case <-timeoutChannel:
+			framework.Failf("Failed to evict the untolerated Pod.")
+			return
+		case podName := <-observedDeletions:
+			if podName != podGroup+"1" {
+				framework.Failf("Unexpected Pod %q was evicted first.", podName)
+				return
+			}
+			framework.Logf("Noticed Pod %q gets evicted.", podName)
+		}
+
+		ginkgo.By("Waiting for the Pod with tolerationSeconds to be deleted after its window")
+		timeoutChannel = time.NewTimer(time.Duration(kubeletPodDeletionDelaySeconds+3*additionalWaitPerDeleteSeconds) * time.Second).C
+		select {
+		case <-timeoutChannel:
+			framework.Failf("Failed to evict the Pod with finite toleration.")
+		case podName := <-observedDeletions:
+			if podName != podGroup+"2" {
+				framework.Failf("Unexpected Pod %q was evicted.", podName)
+				return
+			}
+			framework.Logf("Pod %q was evicted after its toleration window. Test successful", podName)
+		}
+	})
+
 	/*
 		Release : v1.16
 		Testname: Pod Eviction, Toleration limits
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reporters
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"k8s.io/klog"
+)
+
+// FlakeReporter records specs that only passed on retry and quarantined specs
+// whose failures were downgraded to skips, and writes them as JSON so CI can
+// track flakes without scraping test output.
+type FlakeReporter struct {
+	// FlakedSpecs is the number of specs that failed at least once but
+	// eventually passed within the configured flake attempts.
+	FlakedSpecs int `json:"flakedSpecs"`
+	// QuarantinedFailures are the names of quarantined specs that failed and
+	// were reported as skipped.
+	QuarantinedFailures []string `json:"quarantinedFailures"`
+
+	path string
+}
+
+// NewFlakeReporter returns a reporter writing the flake summary to the given path.
+func NewFlakeReporter(path string) *FlakeReporter {
+	return &FlakeReporter{
+		QuarantinedFailures: []string{},
+		path:                path,
+	}
+}
+
+// SpecSuiteWillBegin is implemented as a noop to satisfy the reporter interface.
+func (reporter *FlakeReporter) SpecSuiteWillBegin(cfg config.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// SpecWillRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *FlakeReporter) SpecWillRun(specSummary *types.SpecSummary) {}
+
+// SpecDidComplete collects quarantined specs, which surface as skipped specs
+// whose message carries the quarantine marker set by the framework.
+func (reporter *FlakeReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	if specSummary.Skipped() && strings.Contains(specSummary.Failure.Message, "[quarantined]") {
+		reporter.QuarantinedFailures = append(reporter.QuarantinedFailures, strings.Join(specSummary.ComponentTexts[1:], " "))
+	}
+}
+
+// SpecSuiteDidEnd writes the collected flake summary to disk.
+func (reporter *FlakeReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	reporter.FlakedSpecs = summary.NumberOfFlakedSpecs
+	b, err := json.MarshalIndent(reporter, "", "  ")
+	if err != nil {
+		klog.Errorf("Failed to marshal flake summary: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(reporter.path, b, 0644); err != nil {
+		klog.Errorf("Failed to write flake summary to %v: %v", reporter.path, err)
+	}
+}
+
+// BeforeSuiteDidRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *FlakeReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+// AfterSuiteDidRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *FlakeReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reporters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"k8s.io/klog"
+)
+
+// heartbeat is the periodic status record emitted while a spec is running.
+type heartbeat struct {
+	// Spec is the full name of the currently executing spec.
+	Spec string `json:"spec"`
+	// SpecRunningFor is how long the spec has been executing.
+	SpecRunningFor string `json:"specRunningFor"`
+	// Step is the text of the ginkgo.By step in progress, if any.
+	Step string `json:"step,omitempty"`
+	// StepRunningFor is how long the current step has been executing.
+	StepRunningFor string `json:"stepRunningFor,omitempty"`
+}
+
+// HeartbeatReporter periodically logs the currently executing spec and By
+// step, and optionally POSTs them to a status endpoint, so CI systems can
+// detect hung steps without waiting for the global suite timeout.
+type HeartbeatReporter struct {
+	interval time.Duration
+	url      string
+	client   *http.Client
+	recorder *stepRecorder
+	stop     chan struct{}
+
+	lock      sync.Mutex
+	spec      string
+	specStart time.Time
+}
+
+// NewHeartbeatReporter returns a reporter which emits a heartbeat at the
+// given interval while a spec is running, POSTing it to the given URL if one
+// is set. Like NewJUnitStepsReporter it interposes on the GinkgoWriter, so it
+// must be constructed before any spec runs.
+func NewHeartbeatReporter(interval time.Duration, url string) *HeartbeatReporter {
+	recorder := &stepRecorder{delegate: ginkgo.GinkgoWriter}
+	ginkgo.GinkgoWriter = recorder
+	rep := &HeartbeatReporter{
+		interval: interval,
+		url:      url,
+		recorder: recorder,
+		stop:     make(chan struct{}),
+	}
+	if len(url) > 0 {
+		rep.client = &http.Client{
+			Timeout: time.Second * 10,
+		}
+	}
+	return rep
+}
+
+// SpecSuiteWillBegin starts the heartbeat loop.
+func (reporter *HeartbeatReporter) SpecSuiteWillBegin(cfg config.GinkgoConfigType, summary *types.SuiteSummary) {
+	go reporter.loop()
+}
+
+// SpecWillRun records the spec that heartbeats should report.
+func (reporter *HeartbeatReporter) SpecWillRun(specSummary *types.SpecSummary) {
+	reporter.lock.Lock()
+	defer reporter.lock.Unlock()
+	reporter.spec = strings.Join(specSummary.ComponentTexts[1:], " ")
+	reporter.specStart = time.Now()
+	reporter.recorder.flush()
+}
+
+// SpecDidComplete silences heartbeats until the next spec starts.
+func (reporter *HeartbeatReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	reporter.lock.Lock()
+	defer reporter.lock.Unlock()
+	reporter.spec = ""
+}
+
+// SpecSuiteDidEnd stops the heartbeat loop.
+func (reporter *HeartbeatReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	close(reporter.stop)
+}
+
+func (reporter *HeartbeatReporter) loop() {
+	ticker := time.NewTicker(reporter.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-reporter.stop:
+			return
+		case <-ticker.C:
+			reporter.beat()
+		}
+	}
+}
+
+// beat emits one heartbeat for the spec in flight, if there is one.
+func (reporter *HeartbeatReporter) beat() {
+	reporter.lock.Lock()
+	hb := heartbeat{
+		Spec:           reporter.spec,
+		SpecRunningFor: time.Since(reporter.specStart).Round(time.Second).String(),
+	}
+	reporter.lock.Unlock()
+	if hb.Spec == "" {
+		return
+	}
+	if step, start := reporter.recorder.current(); step != "" {
+		hb.Step = step
+		hb.StepRunningFor = time.Since(start).Round(time.Second).String()
+	}
+	b, err := json.Marshal(hb)
+	if err != nil {
+		klog.Errorf("Failed to marshal heartbeat: %v", err)
+		return
+	}
+	fmt.Println("HEARTBEAT " + string(b))
+	go reporter.post(b)
+}
+
+func (reporter *HeartbeatReporter) post(b []byte) {
+	// If a url and client is set/available then POST to it. Noop otherwise.
+	if reporter.client == nil || len(reporter.url) == 0 {
+		return
+	}
+	resp, err := reporter.client.Post(reporter.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		klog.Errorf("Failed to post heartbeat to %v: %v", reporter.url, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		klog.Errorf("Unexpected response when posting heartbeat to %v: %v", reporter.url, resp.StatusCode)
+	}
+}
+
+// BeforeSuiteDidRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *HeartbeatReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+// AfterSuiteDidRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *HeartbeatReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"k8s.io/klog"
+)
+
+// specResult is the JSON record emitted for every completed spec. It carries
+// the fields downstream result consumers need without requiring them to parse
+// JUnit XML or the raw spec summaries.
+type specResult struct {
+	// Name is the full spec name including all bracketed tags.
+	Name string `json:"name"`
+	// Labels are the bracketed tags ([sig-node], [Conformance], ...) parsed
+	// out of the spec name.
+	Labels []string `json:"labels,omitempty"`
+	// State is one of passed, failed, panicked, timedout, skipped, pending or invalid.
+	State string `json:"state"`
+	// DurationSeconds is the spec's wall clock run time.
+	DurationSeconds float64 `json:"durationSeconds"`
+	// Steps is the ordered ginkgo.By timeline observed in the spec's output.
+	Steps []string `json:"steps,omitempty"`
+	// FailureMessage and FailureLocation are only set for failure states.
+	FailureMessage  string `json:"failureMessage,omitempty"`
+	FailureLocation string `json:"failureLocation,omitempty"`
+}
+
+var specLabelRegexp = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// JSONResultsReporter writes one JSON record per completed spec, so results
+// can be consumed by tooling without parsing JUnit XML.
+type JSONResultsReporter struct {
+	Writer io.Writer
+}
+
+// NewJSONResultsReporterFile returns a reporter which creates the given file
+// and appends a JSON record to it as each spec completes.
+func NewJSONResultsReporterFile(filename string) *JSONResultsReporter {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		klog.Errorf("%#v\n", err)
+		panic(err)
+	}
+	f, err := os.Create(absPath)
+	if err != nil {
+		klog.Errorf("%#v\n", err)
+		panic(err)
+	}
+	return &JSONResultsReporter{Writer: f}
+}
+
+// SpecSuiteWillBegin is implemented as a noop to satisfy the reporter interface.
+func (reporter *JSONResultsReporter) SpecSuiteWillBegin(cfg config.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// SpecWillRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *JSONResultsReporter) SpecWillRun(specSummary *types.SpecSummary) {}
+
+// SpecDidComplete writes the record for the completed spec.
+func (reporter *JSONResultsReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	name := strings.Join(specSummary.ComponentTexts[1:], " ")
+	result := specResult{
+		Name:            name,
+		Labels:          parseSpecLabels(name),
+		State:           specStateName(specSummary.State),
+		DurationSeconds: specSummary.RunTime.Seconds(),
+		Steps:           parseSpecSteps(specSummary.CapturedOutput),
+	}
+	if specSummary.HasFailureState() {
+		result.FailureMessage = specSummary.Failure.Message
+		result.FailureLocation = specSummary.Failure.Location.String()
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		klog.Errorf("Error in results reporter: %v", err)
+		return
+	}
+	if _, err := fmt.Fprintln(reporter.Writer, string(b)); err != nil {
+		klog.Errorf("Error writing spec result: %v", err)
+	}
+}
+
+// SpecSuiteDidEnd is implemented as a noop to satisfy the reporter interface.
+func (reporter *JSONResultsReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
+
+// BeforeSuiteDidRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *JSONResultsReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+// AfterSuiteDidRun closes the output file.
+func (reporter *JSONResultsReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {
+	if c, ok := reporter.Writer.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// parseSpecLabels extracts the bracketed tags from a spec name.
+func parseSpecLabels(name string) []string {
+	var labels []string
+	for _, match := range specLabelRegexp.FindAllStringSubmatch(name, -1) {
+		labels = append(labels, match[1])
+	}
+	return labels
+}
+
+// parseSpecSteps returns the ordered ginkgo.By step texts found in the spec's
+// captured output.
+func parseSpecSteps(output string) []string {
+	var steps []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "STEP: ") {
+			steps = append(steps, strings.TrimPrefix(line, "STEP: "))
+		}
+	}
+	return steps
+}
+
+// specStateName maps a ginkgo spec state to a stable lower case name.
+func specStateName(state types.SpecState) string {
+	switch state {
+	case types.SpecStatePassed:
+		return "passed"
+	case types.SpecStateFailed:
+		return "failed"
+	case types.SpecStatePanicked:
+		return "panicked"
+	case types.SpecStateTimedOut:
+		return "timedout"
+	case types.SpecStateSkipped:
+		return "skipped"
+	case types.SpecStatePending:
+		return "pending"
+	default:
+		return "invalid"
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reporters
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"k8s.io/klog"
+)
+
+// ginkgo.By prints "STEP: <text>" to the GinkgoWriter, with ANSI bold
+// sequences around STEP unless color is disabled.
+var stepLinePrefixes = []string{"STEP: ", "\x1b[1mSTEP\x1b[0m: "}
+
+// stepTiming records when a single ginkgo.By step started and stopped. The
+// stop time of a step is the start of the next step, or the end of the spec
+// for the last one.
+type stepTiming struct {
+	Text  string
+	Start time.Time
+	Stop  time.Time
+}
+
+// stepRecorder is an io.Writer which is installed in front of the
+// GinkgoWriter. It forwards everything to the original writer (so capture and
+// streaming keep working) and records a timestamp whenever it sees a
+// ginkgo.By step line.
+type stepRecorder struct {
+	lock     sync.Mutex
+	delegate io.Writer
+	line     bytes.Buffer
+	steps    []stepTiming
+}
+
+func (r *stepRecorder) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	r.line.Write(p)
+	for {
+		line, err := r.line.ReadString('\n')
+		if err != nil {
+			// Keep the partial line buffered until the rest arrives.
+			r.line.WriteString(line)
+			break
+		}
+		r.sawLine(strings.TrimSuffix(line, "\n"))
+	}
+	r.lock.Unlock()
+	return r.delegate.Write(p)
+}
+
+func (r *stepRecorder) sawLine(line string) {
+	for _, prefix := range stepLinePrefixes {
+		if strings.HasPrefix(line, prefix) {
+			now := time.Now()
+			if n := len(r.steps); n > 0 {
+				r.steps[n-1].Stop = now
+			}
+			r.steps = append(r.steps, stepTiming{Text: strings.TrimPrefix(line, prefix), Start: now})
+			return
+		}
+	}
+}
+
+// current returns the text and start time of the step in progress, if any.
+func (r *stepRecorder) current() (string, time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if n := len(r.steps); n > 0 && r.steps[n-1].Stop.IsZero() {
+		return r.steps[n-1].Text, r.steps[n-1].Start
+	}
+	return "", time.Time{}
+}
+
+// flush stops the open step and returns the steps recorded since the last
+// call, resetting the recorder for the next spec.
+func (r *stepRecorder) flush() []stepTiming {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	steps := r.steps
+	if n := len(steps); n > 0 && steps[n-1].Stop.IsZero() {
+		steps[n-1].Stop = time.Now()
+	}
+	r.steps = nil
+	return steps
+}
+
+type junitStepsCase struct {
+	Name      string  `xml:"name,attr"`
+	Time      float64 `xml:"time,attr"`
+	SystemOut string  `xml:"system-out,omitempty"`
+}
+
+type junitStepsSuite struct {
+	XMLName xml.Name         `xml:"testsuite"`
+	Name    string           `xml:"name,attr"`
+	Tests   int              `xml:"tests,attr"`
+	Cases   []junitStepsCase `xml:"testcase"`
+}
+
+// JUnitStepsReporter emits a supplemental JUnit file in which every
+// testcase's system-out lists the spec's ginkgo.By steps together with their
+// start time and duration, so it is visible which step of a long spec hung or
+// timed out.
+type JUnitStepsReporter struct {
+	path     string
+	recorder *stepRecorder
+	suite    junitStepsSuite
+}
+
+// NewJUnitStepsReporter returns a reporter writing step timings to the given
+// path. It interposes on the GinkgoWriter to observe ginkgo.By output as it
+// happens, so it must be constructed before any spec runs.
+func NewJUnitStepsReporter(path string) *JUnitStepsReporter {
+	recorder := &stepRecorder{delegate: ginkgo.GinkgoWriter}
+	ginkgo.GinkgoWriter = recorder
+	return &JUnitStepsReporter{
+		path:     path,
+		recorder: recorder,
+	}
+}
+
+// SpecSuiteWillBegin names the suite in the output file.
+func (reporter *JUnitStepsReporter) SpecSuiteWillBegin(cfg config.GinkgoConfigType, summary *types.SuiteSummary) {
+	reporter.suite.Name = summary.SuiteDescription
+}
+
+// SpecWillRun drops step timings left over from setup between specs.
+func (reporter *JUnitStepsReporter) SpecWillRun(specSummary *types.SpecSummary) {
+	reporter.recorder.flush()
+}
+
+// SpecDidComplete records the completed spec's step timeline.
+func (reporter *JUnitStepsReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	steps := reporter.recorder.flush()
+	var out strings.Builder
+	for _, step := range steps {
+		fmt.Fprintf(&out, "STEP [%s] [%v]: %s\n", step.Start.Format(time.StampMilli), step.Stop.Sub(step.Start).Round(time.Millisecond), step.Text)
+	}
+	reporter.suite.Cases = append(reporter.suite.Cases, junitStepsCase{
+		Name:      strings.Join(specSummary.ComponentTexts[1:], " "),
+		Time:      specSummary.RunTime.Seconds(),
+		SystemOut: out.String(),
+	})
+}
+
+// SpecSuiteDidEnd writes the collected timings to disk.
+func (reporter *JUnitStepsReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	reporter.suite.Tests = summary.NumberOfTotalSpecs
+	b, err := xml.MarshalIndent(reporter.suite, "", "  ")
+	if err != nil {
+		klog.Errorf("Failed to marshal step timings: %v", err)
+		return
+	}
+	b = append([]byte(xml.Header), b...)
+	if err := ioutil.WriteFile(reporter.path, b, 0644); err != nil {
+		klog.Errorf("Failed to write step timings to %v: %v", reporter.path, err)
+	}
+}
+
+// BeforeSuiteDidRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *JUnitStepsReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+// AfterSuiteDidRun is implemented as a noop to satisfy the reporter interface.
+func (reporter *JUnitStepsReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {}
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2escheduler "k8s.io/kubernetes/test/e2e/framework/scheduler"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+
+	"github.com/onsi/ginkgo"
+)
+
+var _ = SIGDescribe("Custom scheduler [Feature:CustomScheduler]", func() {
+	f := framework.NewDefaultFramework("custom-scheduler")
+
+	ginkgo.It("should schedule pods that request it via schedulerName", func() {
+		schedulerName := "e2e-custom-scheduler"
+
+		ginkgo.By("deploying a second scheduler with its own profile")
+		_, err := e2escheduler.RunCustomScheduler(f, "custom-scheduler", e2escheduler.SimpleConfig(schedulerName))
+		framework.ExpectNoError(err)
+
+		// The default scheduler ignores pods with a foreign schedulerName, so
+		// the pod reaching Running proves the second scheduler handled it.
+		ginkgo.By("creating a pod assigned to the custom scheduler")
+		pod, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "custom-scheduled-pod"},
+			Spec: v1.PodSpec{
+				SchedulerName: schedulerName,
+				Containers: []v1.Container{
+					{
+						Name:  "pause",
+						Image: imageutils.GetPauseImageName(),
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(e2epod.WaitForPodNameRunningInNamespace(f.ClientSet, pod.Name, f.Namespace.Name))
+
+		ginkgo.By("verifying the scheduling event was reported by the custom scheduler")
+		framework.ExpectNoError(wait.Poll(framework.Poll, time.Minute, func() (bool, error) {
+			selector := fmt.Sprintf("involvedObject.name=%s,reason=Scheduled", pod.Name)
+			events, err := f.ClientSet.CoreV1().Events(f.Namespace.Name).List(context.TODO(), metav1.ListOptions{FieldSelector: selector})
+			if err != nil {
+				return false, err
+			}
+			for _, event := range events.Items {
+				if event.Source.Component == schedulerName {
+					return true, nil
+				}
+				framework.Logf("pod %q was scheduled by %q", pod.Name, event.Source.Component)
+			}
+			return false, nil
+		}), "pod %q was not scheduled by %q", pod.Name, schedulerName)
+	})
+})
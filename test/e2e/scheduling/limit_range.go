@@ -23,18 +23,26 @@ import (
 	"strconv"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
 	watchtools "k8s.io/client-go/tools/watch"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2edeployment "k8s.io/kubernetes/test/e2e/framework/deployment"
+	e2eevents "k8s.io/kubernetes/test/e2e/framework/events"
+	e2ejob "k8s.io/kubernetes/test/e2e/framework/job"
+	e2ematchers "k8s.io/kubernetes/test/e2e/framework/matchers"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2eservice "k8s.io/kubernetes/test/e2e/framework/service"
+	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
 	imageutils "k8s.io/kubernetes/test/utils/image"
 
 	"github.com/onsi/ginkgo"
@@ -126,8 +134,7 @@ var _ = SIGDescribe("LimitRange", func() {
 		framework.ExpectNoError(err)
 		expected := v1.ResourceRequirements{Requests: defaultRequest, Limits: defaultLimit}
 		actual := v1.ResourceRequirements{Requests: limitRange.Spec.Limits[0].DefaultRequest, Limits: limitRange.Spec.Limits[0].Default}
-		err = equalResourceRequirement(expected, actual)
-		framework.ExpectNoError(err)
+		gomega.Expect(actual).To(e2ematchers.HaveEquivalentResources(expected))
 
 		ginkgo.By("Creating a Pod with no resource requirements")
 		pod := newTestPod("pod-no-resources", v1.ResourceList{}, v1.ResourceList{})
@@ -138,12 +145,7 @@ var _ = SIGDescribe("LimitRange", func() {
 		pod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Get(context.TODO(), pod.Name, metav1.GetOptions{})
 		framework.ExpectNoError(err)
 		for i := range pod.Spec.Containers {
-			err = equalResourceRequirement(expected, pod.Spec.Containers[i].Resources)
-			if err != nil {
-				// Print the pod to help in debugging.
-				framework.Logf("Pod %+v does not have the expected requirements", pod)
-				framework.ExpectNoError(err)
-			}
+			gomega.Expect(pod.Spec.Containers[i].Resources).To(e2ematchers.HaveEquivalentResources(expected), "Pod %+v does not have the expected requirements", pod)
 		}
 
 		ginkgo.By("Creating a Pod with partial resource requirements")
@@ -159,12 +161,7 @@ var _ = SIGDescribe("LimitRange", func() {
 		// This means that the LimitRange.DefaultRequest will ONLY take affect if a container.resources.limit is not supplied
 		expected = v1.ResourceRequirements{Requests: getResourceList("300m", "150Mi", "150Gi"), Limits: getResourceList("300m", "500Mi", "500Gi")}
 		for i := range pod.Spec.Containers {
-			err = equalResourceRequirement(expected, pod.Spec.Containers[i].Resources)
-			if err != nil {
-				// Print the pod to help in debugging.
-				framework.Logf("Pod %+v does not have the expected requirements", pod)
-				framework.ExpectNoError(err)
-			}
+			gomega.Expect(pod.Spec.Containers[i].Resources).To(e2ematchers.HaveEquivalentResources(expected), "Pod %+v does not have the expected requirements", pod)
 		}
 
 		ginkgo.By("Failing to create a Pod with less than min resources")
@@ -241,31 +238,295 @@ var _ = SIGDescribe("LimitRange", func() {
 		framework.ExpectNoError(err)
 	})
 
+	ginkgo.It("should apply defaults to pods created through workload controllers and surface template violations", func() {
+		ginkgo.By("Creating a LimitRange")
+		min := getResourceList("50m", "100Mi", "")
+		max := getResourceList("500m", "500Mi", "")
+		defaultLimit := getResourceList("500m", "500Mi", "")
+		defaultRequest := getResourceList("100m", "200Mi", "")
+		maxLimitRequestRatio := v1.ResourceList{}
+		value := strconv.FormatInt(time.Now().UnixNano(), 10)
+		limitRange := newLimitRange("limit-range", value, v1.LimitTypeContainer,
+			min, max,
+			defaultLimit, defaultRequest,
+			maxLimitRequestRatio)
+		_, err := f.ClientSet.CoreV1().LimitRanges(f.Namespace.Name).Create(context.TODO(), limitRange, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		expected := v1.ResourceRequirements{Requests: defaultRequest, Limits: defaultLimit}
+
+		ginkgo.By("Creating a Deployment with no resource requirements")
+		deployment := e2edeployment.NewDeployment("limitrange-deployment", 2,
+			map[string]string{"name": "limitrange-deployment"},
+			"agnhost", imageutils.GetE2EImage(imageutils.Agnhost), appsv1.RecreateDeploymentStrategyType)
+		deployment, err = f.ClientSet.AppsV1().Deployments(f.Namespace.Name).Create(context.TODO(), deployment, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(e2edeployment.WaitForDeploymentComplete(f.ClientSet, deployment))
+
+		ginkgo.By("Ensuring the Deployment's pods have the default resources applied")
+		pods, err := e2edeployment.GetPodsForDeployment(f.ClientSet, deployment)
+		framework.ExpectNoError(err)
+		gomega.Expect(pods.Items).NotTo(gomega.BeEmpty())
+		for _, pod := range pods.Items {
+			for i := range pod.Spec.Containers {
+				gomega.Expect(pod.Spec.Containers[i].Resources).To(e2ematchers.HaveEquivalentResources(expected), "Pod %+v does not have the expected requirements", pod)
+			}
+		}
+
+		ginkgo.By("Creating a Job with no resource requirements")
+		job := e2ejob.NewTestJob("notTerminate", "limitrange-job", v1.RestartPolicyNever, 1, 1, nil, 6)
+		job, err = e2ejob.CreateJob(f.ClientSet, f.Namespace.Name, job)
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(e2ejob.WaitForAllJobPodsRunning(f.ClientSet, f.Namespace.Name, job.Name, 1))
+
+		ginkgo.By("Ensuring the Job's pods have the default resources applied")
+		jobPods, err := e2ejob.GetJobPods(f.ClientSet, f.Namespace.Name, job.Name)
+		framework.ExpectNoError(err)
+		gomega.Expect(jobPods.Items).NotTo(gomega.BeEmpty())
+		for _, pod := range jobPods.Items {
+			for i := range pod.Spec.Containers {
+				gomega.Expect(pod.Spec.Containers[i].Resources).To(e2ematchers.HaveEquivalentResources(expected), "Pod %+v does not have the expected requirements", pod)
+			}
+		}
+
+		ginkgo.By("Creating a ReplicaSet whose template exceeds the LimitRange maximum")
+		rs := newLimitExceedingReplicaSet("limitrange-rs", getResourceList("1", "1Gi", ""))
+		rs, err = f.ClientSet.AppsV1().ReplicaSets(f.Namespace.Name).Create(context.TODO(), rs, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "the ReplicaSet itself should be admitted, only its pods are rejected")
+
+		ginkgo.By("Ensuring the pod creation failure is surfaced as a FailedCreate event")
+		_, err = e2eevents.WaitForEvent(f.ClientSet, f.Namespace.Name, e2eevents.EventMatcher{
+			InvolvedObjectKind: "ReplicaSet",
+			InvolvedObjectName: rs.Name,
+			Reason:             "FailedCreate",
+			MessagePattern:     "maximum cpu usage",
+		}, 2*time.Minute)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Ensuring the pod creation failure is surfaced as a ReplicaFailure condition")
+		err = wait.Poll(framework.Poll, 2*time.Minute, func() (bool, error) {
+			rs, err = f.ClientSet.AppsV1().ReplicaSets(f.Namespace.Name).Get(context.TODO(), rs.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			for _, cond := range rs.Status.Conditions {
+				if cond.Type == appsv1.ReplicaSetReplicaFailure && cond.Status == v1.ConditionTrue {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		framework.ExpectNoError(err, "ReplicaSet %s never reported a ReplicaFailure condition: %+v", rs.Name, rs.Status.Conditions)
+	})
+
+	ginkgo.It("should enforce Container-type and Pod-type items of one LimitRange simultaneously", func() {
+		ginkgo.By("Creating a LimitRange with both Container-type and Pod-type items")
+		defaultLimit := getResourceList("500m", "500Mi", "")
+		defaultRequest := getResourceList("100m", "200Mi", "")
+		containerMax := getResourceList("600m", "600Mi", "")
+		podMax := getResourceList("1", "1Gi", "")
+		limitRange := &v1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "limit-range-multi",
+			},
+			Spec: v1.LimitRangeSpec{
+				Limits: []v1.LimitRangeItem{
+					{
+						Type:           v1.LimitTypeContainer,
+						Max:            containerMax,
+						Default:        defaultLimit,
+						DefaultRequest: defaultRequest,
+					},
+					{
+						Type: v1.LimitTypePod,
+						Max:  podMax,
+					},
+				},
+			},
+		}
+		_, err := f.ClientSet.CoreV1().LimitRanges(f.Namespace.Name).Create(context.TODO(), limitRange, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Creating a Pod whose container defaults fit under the pod-level maximum")
+		pod := newTestMultiContainerPod("two-containers", 2, v1.ResourceList{}, v1.ResourceList{})
+		pod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Ensuring the container defaults were applied")
+		expected := v1.ResourceRequirements{Requests: defaultRequest, Limits: defaultLimit}
+		for i := range pod.Spec.Containers {
+			gomega.Expect(pod.Spec.Containers[i].Resources).To(e2ematchers.HaveEquivalentResources(expected), "Pod %+v does not have the expected requirements", pod)
+		}
+
+		ginkgo.By("Failing to create a Pod whose container defaults would exceed the pod-level maximum")
+		pod = newTestMultiContainerPod("three-containers", 3, v1.ResourceList{}, v1.ResourceList{})
+		_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectError(err, "each defaulted container is within the container maximum, but their sum exceeds the pod maximum")
+
+		ginkgo.By("Failing to create a Pod whose explicit container limits exceed the pod-level maximum")
+		pod = newTestMultiContainerPod("sum-above-pod-max", 2, v1.ResourceList{}, getResourceList("550m", "550Mi", ""))
+		_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectError(err)
+
+		ginkgo.By("Failing to create a Pod whose single container exceeds the container-level maximum")
+		pod = newTestMultiContainerPod("above-container-max", 1, v1.ResourceList{}, getResourceList("700m", "700Mi", ""))
+		_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectError(err, "the container is within the pod maximum but exceeds the container maximum")
+	})
+
+	ginkgo.It("should apply defaults to init containers and enforce min/max on them", func() {
+		ginkgo.By("Creating a LimitRange")
+		min := getResourceList("50m", "100Mi", "")
+		max := getResourceList("500m", "500Mi", "")
+		defaultLimit := getResourceList("500m", "500Mi", "")
+		defaultRequest := getResourceList("100m", "200Mi", "")
+		maxLimitRequestRatio := v1.ResourceList{}
+		value := strconv.FormatInt(time.Now().UnixNano(), 10)
+		limitRange := newLimitRange("limit-range", value, v1.LimitTypeContainer,
+			min, max,
+			defaultLimit, defaultRequest,
+			maxLimitRequestRatio)
+		_, err := f.ClientSet.CoreV1().LimitRanges(f.Namespace.Name).Create(context.TODO(), limitRange, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Creating a Pod with an init container with no resource requirements")
+		pod := newTestPod("init-defaults", v1.ResourceList{}, v1.ResourceList{})
+		pod.Spec.InitContainers = []v1.Container{newTestInitContainer(v1.ResourceList{})}
+		pod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Ensuring the init container has the default resources applied")
+		expected := v1.ResourceRequirements{Requests: defaultRequest, Limits: defaultLimit}
+		gomega.Expect(pod.Spec.InitContainers[0].Resources).To(e2ematchers.HaveEquivalentResources(expected), "init container of Pod %+v does not have the expected requirements", pod)
+
+		ginkgo.By("Failing to create a Pod whose init container exceeds the maximum")
+		pod = newTestPod("init-above-max", v1.ResourceList{}, v1.ResourceList{})
+		pod.Spec.InitContainers = []v1.Container{newTestInitContainer(getResourceList("600m", "600Mi", ""))}
+		_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectError(err)
+
+		ginkgo.By("Failing to create a Pod whose init container requests less than the minimum")
+		pod = newTestPod("init-below-min", v1.ResourceList{}, v1.ResourceList{})
+		pod.Spec.InitContainers = []v1.Container{newTestInitContainer(getResourceList("10m", "50Mi", ""))}
+		_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectError(err)
+	})
+
+	ginkgo.It("should admit ephemeral containers without defaulting them under a restrictive LimitRange", func() {
+		e2eskipper.SkipUnlessResourceServed(f.ClientSet.Discovery(), schema.GroupVersionResource{Version: "v1", Resource: "pods/ephemeralcontainers"})
+
+		ginkgo.By("Creating a LimitRange")
+		min := getResourceList("50m", "100Mi", "")
+		max := getResourceList("500m", "500Mi", "")
+		defaultLimit := getResourceList("500m", "500Mi", "")
+		defaultRequest := getResourceList("100m", "200Mi", "")
+		maxLimitRequestRatio := v1.ResourceList{}
+		value := strconv.FormatInt(time.Now().UnixNano(), 10)
+		limitRange := newLimitRange("limit-range", value, v1.LimitTypeContainer,
+			min, max,
+			defaultLimit, defaultRequest,
+			maxLimitRequestRatio)
+		_, err := f.ClientSet.CoreV1().LimitRanges(f.Namespace.Name).Create(context.TODO(), limitRange, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Creating a running Pod")
+		pod := newTestPod("ephemeral-limitrange", v1.ResourceList{}, v1.ResourceList{})
+		pod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(e2epod.WaitForPodNameRunningInNamespace(f.ClientSet, pod.Name, f.Namespace.Name))
+
+		ginkgo.By("Adding an ephemeral container through the ephemeralcontainers subresource")
+		ec, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).GetEphemeralContainers(context.TODO(), pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		ec.EphemeralContainers = append(ec.EphemeralContainers, v1.EphemeralContainer{
+			EphemeralContainerCommon: v1.EphemeralContainerCommon{
+				Name:    "debugger",
+				Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+				Command: []string{"sh", "-c", "sleep 3600"},
+			},
+		})
+		_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).UpdateEphemeralContainers(context.TODO(), pod.Name, ec, metav1.UpdateOptions{})
+		framework.ExpectNoError(err, "the LimitRange must not block ephemeral containers, which cannot declare resources")
+
+		// LimitRange defaulting does not apply to ephemeral containers: the
+		// API forbids them from declaring resource requirements, so the
+		// admitted container must come back with none set.
+		ginkgo.By("Ensuring the ephemeral container was admitted without resource requirements")
+		pod, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(len(pod.Spec.EphemeralContainers), 1)
+		gomega.Expect(pod.Spec.EphemeralContainers[0].Resources.Requests).To(gomega.BeEmpty())
+		gomega.Expect(pod.Spec.EphemeralContainers[0].Resources.Limits).To(gomega.BeEmpty())
+	})
+
 })
 
-func equalResourceRequirement(expected v1.ResourceRequirements, actual v1.ResourceRequirements) error {
-	framework.Logf("Verifying requests: expected %v with actual %v", expected.Requests, actual.Requests)
-	err := equalResourceList(expected.Requests, actual.Requests)
-	if err != nil {
-		return err
+// newTestMultiContainerPod returns a pod with count pause containers, each
+// with the given requests and limits.
+func newTestMultiContainerPod(name string, count int, requests v1.ResourceList, limits v1.ResourceList) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
 	}
-	framework.Logf("Verifying limits: expected %v with actual %v", expected.Limits, actual.Limits)
-	err = equalResourceList(expected.Limits, actual.Limits)
-	return err
+	for i := 0; i < count; i++ {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{
+			Name:  fmt.Sprintf("pause-%d", i),
+			Image: imageutils.GetPauseImageName(),
+			Resources: v1.ResourceRequirements{
+				Requests: requests,
+				Limits:   limits,
+			},
+		})
+	}
+	e2epod.SetDefaultNodeOSSelector(&pod.Spec)
+	return pod
 }
 
-func equalResourceList(expected v1.ResourceList, actual v1.ResourceList) error {
-	for k, v := range expected {
-		if actualValue, found := actual[k]; !found || (v.Cmp(actualValue) != 0) {
-			return fmt.Errorf("resource %v expected %v actual %v", k, v.String(), actualValue.String())
-		}
+// newTestInitContainer returns an init container that exits immediately,
+// requesting the given resources.
+func newTestInitContainer(requests v1.ResourceList) v1.Container {
+	return v1.Container{
+		Name:    "init",
+		Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+		Command: []string{"sh", "-c", "true"},
+		Resources: v1.ResourceRequirements{
+			Requests: requests,
+		},
 	}
-	for k, v := range actual {
-		if expectedValue, found := expected[k]; !found || (v.Cmp(expectedValue) != 0) {
-			return fmt.Errorf("resource %v expected %v actual %v", k, expectedValue.String(), v.String())
-		}
+}
+
+// newLimitExceedingReplicaSet returns a ReplicaSet whose container requests
+// the given resources, intended to exceed a LimitRange maximum so that pod
+// creation is rejected by admission.
+func newLimitExceedingReplicaSet(name string, requests v1.ResourceList) *appsv1.ReplicaSet {
+	replicas := int32(1)
+	podLabels := map[string]string{"name": name}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  name,
+							Image: imageutils.GetPauseImageName(),
+							Resources: v1.ResourceRequirements{
+								Requests: requests,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
-	return nil
 }
 
 func getResourceList(cpu, memory string, ephemeralStorage string) v1.ResourceList {
@@ -311,7 +572,7 @@ func newLimitRange(name, value string, limitType v1.LimitType,
 
 // newTestPod returns a pod that has the specified requests and limits
 func newTestPod(name string, requests v1.ResourceList, limits v1.ResourceList) *v1.Pod {
-	return &v1.Pod{
+	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
 		},
@@ -328,4 +589,6 @@ func newTestPod(name string, requests v1.ResourceList, limits v1.ResourceList) *
 			},
 		},
 	}
+	e2epod.SetDefaultNodeOSSelector(&pod.Spec)
+	return pod
 }
@@ -25,11 +25,13 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
@@ -660,8 +662,212 @@ var _ = SIGDescribe("SchedulerPreemption [Serial]", func() {
 			}
 		})
 	})
+
+	ginkgo.Context("PreemptionPolicy Never [Feature:NonPreemptingPriority]", func() {
+		var fakeRes v1.ResourceName = "example.com/fakeNeverRes"
+		var node *v1.Node
+		nonPreemptingPriorityClassName := "sched-preemption-never-priority"
+
+		ginkgo.BeforeEach(func() {
+			ginkgo.By("Trying to get a node which can run a pod")
+			nodeName := GetNodeThatCanRunPod(f)
+			var err error
+			node, err = cs.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+			framework.ExpectNoError(err)
+			nodeCopy := node.DeepCopy()
+			// force it to update
+			nodeCopy.ResourceVersion = "0"
+			ginkgo.By(fmt.Sprintf("Apply 10 fake resource to node %v.", node.Name))
+			nodeCopy.Status.Capacity[fakeRes] = resource.MustParse("10")
+			node, err = cs.CoreV1().Nodes().UpdateStatus(context.TODO(), nodeCopy, metav1.UpdateOptions{})
+			framework.ExpectNoError(err)
+
+			preemptNever := v1.PreemptNever
+			_, err = cs.SchedulingV1().PriorityClasses().Create(context.TODO(), &schedulingv1.PriorityClass{
+				ObjectMeta:       metav1.ObjectMeta{Name: nonPreemptingPriorityClassName},
+				Value:            highPriority,
+				PreemptionPolicy: &preemptNever,
+			}, metav1.CreateOptions{})
+			framework.ExpectEqual(err == nil || apierrors.IsAlreadyExists(err), true)
+		})
+
+		ginkgo.AfterEach(func() {
+			cs.SchedulingV1().PriorityClasses().Delete(context.TODO(), nonPreemptingPriorityClassName, *metav1.NewDeleteOptions(0))
+			if node != nil {
+				nodeCopy := node.DeepCopy()
+				// force it to update
+				nodeCopy.ResourceVersion = "0"
+				delete(nodeCopy.Status.Capacity, fakeRes)
+				_, err := cs.CoreV1().Nodes().UpdateStatus(context.TODO(), nodeCopy, metav1.UpdateOptions{})
+				framework.ExpectNoError(err)
+			}
+		})
+
+		// A pod whose priority class carries preemptionPolicy Never must wait in the
+		// scheduling queue until resources free up, rather than evicting lower
+		// priority pods, even though its priority would otherwise allow preemption.
+		ginkgo.It("validates pod with PreemptionPolicy Never waits instead of preempting lower priority pods", func() {
+			nodeAffinity := nodeAffinityForName(node.Name)
+
+			ginkgo.By("Create a low priority pod consuming most of the node's fake resource")
+			victim := runPausePod(f, pausePodConfig{
+				Name:              "victim-pod",
+				PriorityClassName: lowPriorityClassName,
+				Affinity:          nodeAffinity,
+				Resources: &v1.ResourceRequirements{
+					Requests: v1.ResourceList{fakeRes: resource.MustParse("8")},
+					Limits:   v1.ResourceList{fakeRes: resource.MustParse("8")},
+				},
+			})
+
+			ginkgo.By("Run a non-preempting pod that does not fit in the remaining fake resource")
+			preemptor := createPausePod(f, pausePodConfig{
+				Name:              "non-preemptor-pod",
+				PriorityClassName: nonPreemptingPriorityClassName,
+				Affinity:          nodeAffinity,
+				Resources: &v1.ResourceRequirements{
+					Requests: v1.ResourceList{fakeRes: resource.MustParse("4")},
+					Limits:   v1.ResourceList{fakeRes: resource.MustParse("4")},
+				},
+			})
+
+			ginkgo.By("Verify the non-preempting pod is reported as unschedulable")
+			framework.ExpectNoError(e2epod.WaitForPodNameUnschedulableInNamespace(cs, preemptor.Name, ns))
+
+			ginkgo.By("Verify the lower priority pod was not preempted")
+			livePod, err := cs.CoreV1().Pods(victim.Namespace).Get(context.TODO(), victim.Name, metav1.GetOptions{})
+			framework.ExpectNoError(err)
+			gomega.Expect(livePod.DeletionTimestamp).To(gomega.BeNil())
+
+			ginkgo.By("Remove the lower priority pod to free up the fake resource")
+			framework.ExpectNoError(e2epod.DeletePodWithWait(cs, livePod))
+
+			ginkgo.By("Wait for the non-preempting pod to be scheduled")
+			framework.ExpectNoError(e2epod.WaitForPodRunningInNamespace(cs, preemptor))
+		})
+	})
+
+	ginkgo.Context("PodDisruptionBudget preemption preference", func() {
+		var fakeRes v1.ResourceName = "example.com/fakePDBRes"
+		var node *v1.Node
+
+		ginkgo.BeforeEach(func() {
+			ginkgo.By("Trying to get a node which can run a pod")
+			nodeName := GetNodeThatCanRunPod(f)
+			var err error
+			node, err = cs.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+			framework.ExpectNoError(err)
+			nodeCopy := node.DeepCopy()
+			// force it to update
+			nodeCopy.ResourceVersion = "0"
+			ginkgo.By(fmt.Sprintf("Apply 10 fake resource to node %v.", node.Name))
+			nodeCopy.Status.Capacity[fakeRes] = resource.MustParse("10")
+			node, err = cs.CoreV1().Nodes().UpdateStatus(context.TODO(), nodeCopy, metav1.UpdateOptions{})
+			framework.ExpectNoError(err)
+		})
+
+		ginkgo.AfterEach(func() {
+			if node != nil {
+				nodeCopy := node.DeepCopy()
+				// force it to update
+				nodeCopy.ResourceVersion = "0"
+				delete(nodeCopy.Status.Capacity, fakeRes)
+				_, err := cs.CoreV1().Nodes().UpdateStatus(context.TODO(), nodeCopy, metav1.UpdateOptions{})
+				framework.ExpectNoError(err)
+			}
+		})
+
+		// When the scheduler has a choice of victims, it should prefer evicting
+		// pods whose eviction does not violate a PodDisruptionBudget. This is a
+		// best-effort preference, but with exactly one protected and one
+		// unprotected candidate it must pick the unprotected one.
+		ginkgo.It("validates victims not protected by a PodDisruptionBudget are preferred for preemption", func() {
+			nodeAffinity := nodeAffinityForName(node.Name)
+			protectedLabels := map[string]string{"e2e-pdb-protected": "true"}
+			victimRes := &v1.ResourceRequirements{
+				Requests: v1.ResourceList{fakeRes: resource.MustParse("4")},
+				Limits:   v1.ResourceList{fakeRes: resource.MustParse("4")},
+			}
+
+			ginkgo.By("Create a medium priority pod protected by a PodDisruptionBudget")
+			protected := runPausePod(f, pausePodConfig{
+				Name:              "protected-pod",
+				Labels:            protectedLabels,
+				PriorityClassName: mediumPriorityClassName,
+				Affinity:          nodeAffinity,
+				Resources:         victimRes,
+			})
+
+			pdb := &policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "protected-pod-pdb",
+					Namespace: ns,
+				},
+				Spec: policyv1beta1.PodDisruptionBudgetSpec{
+					Selector:     &metav1.LabelSelector{MatchLabels: protectedLabels},
+					MinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+				},
+			}
+			_, err := cs.PolicyV1beta1().PodDisruptionBudgets(ns).Create(context.TODO(), pdb, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+
+			ginkgo.By("Wait for the PodDisruptionBudget to observe the protected pod")
+			err = wait.PollImmediate(framework.Poll, framework.PollShortTimeout, func() (bool, error) {
+				pdb, err := cs.PolicyV1beta1().PodDisruptionBudgets(ns).Get(context.TODO(), pdb.Name, metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+				return pdb.Status.ExpectedPods == 1, nil
+			})
+			framework.ExpectNoError(err)
+
+			ginkgo.By("Create a medium priority pod not covered by any PodDisruptionBudget")
+			bystander := runPausePod(f, pausePodConfig{
+				Name:              "bystander-pod",
+				PriorityClassName: mediumPriorityClassName,
+				Affinity:          nodeAffinity,
+				Resources:         victimRes,
+			})
+
+			ginkgo.By("Run a high priority pod that requires preempting one of the two pods")
+			runPausePod(f, pausePodConfig{
+				Name:              "preemptor-pod",
+				PriorityClassName: highPriorityClassName,
+				Affinity:          nodeAffinity,
+				Resources:         victimRes,
+			})
+
+			ginkgo.By("Verify the pod without a PodDisruptionBudget was the one preempted")
+			preemptedPod, err := cs.CoreV1().Pods(bystander.Namespace).Get(context.TODO(), bystander.Name, metav1.GetOptions{})
+			podPreempted := (err != nil && apierrors.IsNotFound(err)) ||
+				(err == nil && preemptedPod.DeletionTimestamp != nil)
+			framework.ExpectEqual(podPreempted, true)
+
+			livePod, err := cs.CoreV1().Pods(protected.Namespace).Get(context.TODO(), protected.Name, metav1.GetOptions{})
+			framework.ExpectNoError(err)
+			gomega.Expect(livePod.DeletionTimestamp).To(gomega.BeNil())
+		})
+	})
 })
 
+// nodeAffinityForName returns a node affinity that restricts scheduling to the
+// node with the given name.
+func nodeAffinityForName(name string) *v1.Affinity {
+	return &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchFields: []v1.NodeSelectorRequirement{
+							{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{name}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 type pauseRSConfig struct {
 	Replicas  int32
 	PodConfig pausePodConfig
@@ -31,12 +31,14 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2eevents "k8s.io/kubernetes/test/e2e/framework/events"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2epv "k8s.io/kubernetes/test/e2e/framework/pv"
 	"k8s.io/kubernetes/test/e2e/storage/drivers"
@@ -94,6 +96,7 @@ var _ = utils.SIGDescribe("CSI mock volume", func() {
 		enableNodeExpansion bool // enable node expansion for CSI mock driver
 		// just disable resizing on driver it overrides enableResizing flag for CSI mock driver
 		disableResizingOnDriver bool
+		enableVolumeHealth      bool
 		javascriptHooks         map[string]string
 	}
 
@@ -129,6 +132,7 @@ var _ = utils.SIGDescribe("CSI mock volume", func() {
 			DisableAttach:       tp.disableAttach,
 			EnableResizing:      tp.enableResizing,
 			EnableNodeExpansion: tp.enableNodeExpansion,
+			EnableVolumeHealth:  tp.enableVolumeHealth,
 			JavascriptHooks:     tp.javascriptHooks,
 		}
 
@@ -411,6 +415,13 @@ var _ = utils.SIGDescribe("CSI mock volume", func() {
 			gomega.Expect(pod3).NotTo(gomega.BeNil(), "while creating third pod")
 			err = waitForMaxVolumeCondition(pod3, m.cs)
 			framework.ExpectNoError(err, "while waiting for max volume condition on pod : %+v", pod3)
+
+			ginkgo.By("Deleting the second pod to free volume capacity on the node")
+			err = e2epod.DeletePodWithWait(m.cs, pod2)
+			framework.ExpectNoError(err, "Failed to delete pod2: %v", err)
+
+			err = e2epod.WaitForPodNameRunningInNamespace(m.cs, pod3.Name, pod3.Namespace)
+			framework.ExpectNoError(err, "Failed to start pod3 after freeing volume capacity: %v", err)
 		})
 	})
 
@@ -937,6 +948,70 @@ var _ = utils.SIGDescribe("CSI mock volume", func() {
 			})
 		}
 	})
+
+	ginkgo.Context("CSI fault injection", func() {
+		ginkgo.It("should retry ControllerPublishVolume until injected failures stop", func() {
+			hooks, err := drivers.FaultInjectionHooks([]drivers.CSIFault{
+				{Method: "ControllerPublishVolume", ErrorCode: "DEADLINEEXCEEDED", FailCalls: 2},
+			})
+			framework.ExpectNoError(err, "while rendering fault injection hooks")
+			init(testParameters{
+				registerDriver:  true,
+				javascriptHooks: hooks,
+			})
+			defer cleanup()
+
+			_, claim, pod := createPod(false)
+			gomega.Expect(pod).NotTo(gomega.BeNil(), "while creating pod")
+
+			err = e2epv.WaitForPersistentVolumeClaimPhase(v1.ClaimBound, f.ClientSet, f.Namespace.Name, claim.Name, time.Second, framework.ClaimProvisionTimeout)
+			framework.ExpectNoError(err, "while waiting for PVC to get provisioned")
+
+			ginkgo.By("Waiting for pod to run despite the injected attach failures")
+			err = e2epod.WaitForPodNameRunningInNamespace(m.cs, pod.Name, pod.Namespace)
+			framework.ExpectNoError(err, "failed to start pod")
+		})
+	})
+
+	// The external health monitor controller and agent are not deployed as
+	// part of the mock driver harness, so this only runs against clusters
+	// where they are deployed next to the mock driver; hence the feature tag.
+	ginkgo.Context("CSI volume health [Feature:CSIVolumeHealth]", func() {
+		ginkgo.It("should report events on the PVC and pod for an abnormal volume condition", func() {
+			init(testParameters{
+				registerDriver:     true,
+				disableAttach:      true,
+				enableVolumeHealth: true,
+			})
+			defer cleanup()
+
+			_, claim, pod := createPod(false)
+			gomega.Expect(pod).NotTo(gomega.BeNil(), "while creating pod")
+
+			err := e2epv.WaitForPersistentVolumeClaimPhase(v1.ClaimBound, f.ClientSet, f.Namespace.Name, claim.Name, time.Second, framework.ClaimProvisionTimeout)
+			framework.ExpectNoError(err, "while waiting for PVC to get provisioned")
+			err = e2epod.WaitForPodNameRunningInNamespace(m.cs, pod.Name, pod.Namespace)
+			framework.ExpectNoError(err, "while waiting for pod to be running")
+
+			ginkgo.By("Waiting for an abnormal volume condition event on the PVC")
+			pvcSelector := fields.Set{
+				"involvedObject.kind": "PersistentVolumeClaim",
+				"involvedObject.name": claim.Name,
+				"reason":              "VolumeConditionAbnormal",
+			}.AsSelector().String()
+			err = e2eevents.WaitTimeoutForEvent(m.cs, claim.Namespace, pvcSelector, "", csiPodRunningTimeout)
+			framework.ExpectNoError(err, "while waiting for event on PVC %s", claim.Name)
+
+			ginkgo.By("Waiting for an abnormal volume condition event on the pod")
+			podSelector := fields.Set{
+				"involvedObject.kind": "Pod",
+				"involvedObject.name": pod.Name,
+				"reason":              "VolumeConditionAbnormal",
+			}.AsSelector().String()
+			err = e2eevents.WaitTimeoutForEvent(m.cs, pod.Namespace, podSelector, "", csiPodRunningTimeout)
+			framework.ExpectNoError(err, "while waiting for event on pod %s", pod.Name)
+		})
+	})
 })
 
 func waitForMaxVolumeCondition(pod *v1.Pod, cs clientset.Interface) error {
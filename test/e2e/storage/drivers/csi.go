@@ -237,6 +237,7 @@ type mockCSIDriver struct {
 	attachLimit         int
 	enableTopology      bool
 	enableNodeExpansion bool
+	enableVolumeHealth  bool
 	cleanupHandle       framework.CleanupActionHandle
 	javascriptHooks     map[string]string
 }
@@ -250,6 +251,7 @@ type CSIMockDriverOpts struct {
 	EnableTopology      bool
 	EnableResizing      bool
 	EnableNodeExpansion bool
+	EnableVolumeHealth  bool
 	JavascriptHooks     map[string]string
 }
 
@@ -300,6 +302,7 @@ func InitMockCSIDriver(driverOpts CSIMockDriverOpts) testsuites.TestDriver {
 		attachable:          !driverOpts.DisableAttach,
 		attachLimit:         driverOpts.AttachLimit,
 		enableNodeExpansion: driverOpts.EnableNodeExpansion,
+		enableVolumeHealth:  driverOpts.EnableVolumeHealth,
 		javascriptHooks:     driverOpts.JavascriptHooks,
 	}
 }
@@ -358,6 +361,12 @@ func (m *mockCSIDriver) PrepareTest(f *framework.Framework) (*testsuites.PerTest
 		containerArgs = append(containerArgs, "--node-expand-required=true")
 	}
 
+	if m.enableVolumeHealth {
+		// Makes the mock driver report an abnormal volume condition in
+		// ListVolumes and NodeGetVolumeStats responses.
+		containerArgs = append(containerArgs, "--enable-volume-condition")
+	}
+
 	// Create a config map with javascript hooks. Create it even when javascriptHooks
 	// are empty, so we can unconditionally add it to the mock pod.
 	const hooksConfigMapName = "mock-driver-hooks"
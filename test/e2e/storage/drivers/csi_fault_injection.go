@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// csiFaultHookNames maps CSI method names to the mock driver's javascript
+// hook keys.
+var csiFaultHookNames = map[string]string{
+	"CreateVolume":              "createVolumeStart",
+	"DeleteVolume":              "deleteVolumeStart",
+	"ControllerPublishVolume":   "controllerPublishVolumeStart",
+	"ControllerUnpublishVolume": "controllerUnpublishVolumeStart",
+	"NodeStageVolume":           "nodeStageVolumeStart",
+	"NodeUnstageVolume":         "nodeUnstageVolumeStart",
+	"NodePublishVolume":         "nodePublishVolumeStart",
+	"NodeUnpublishVolume":       "nodeUnpublishVolumeStart",
+}
+
+// CSIFault describes deterministic fault injection for one CSI method of the
+// mock driver. Negative-path tests (attach retry, exponential backoff) build
+// a slice of these instead of hand-writing javascript hooks.
+type CSIFault struct {
+	// Method is the CSI method to inject the fault into, e.g. "CreateVolume"
+	// or "NodeStageVolume".
+	Method string
+	// ErrorCode is the name of the gRPC code constant the hook returns while
+	// the fault is active, as understood by the mock driver's hook
+	// environment, e.g. "DEADLINEEXCEEDED" or "RESOURCEEXHAUSTED".
+	ErrorCode string
+	// FailCalls is the number of leading calls that fail with ErrorCode;
+	// later calls succeed. Zero makes every call fail.
+	FailCalls int
+	// DelaySeconds delays every call to the method before responding.
+	// Delaying selected methods for different durations also lets a test
+	// provoke out-of-order responses deterministically.
+	DelaySeconds int
+}
+
+// FaultInjectionHooks renders the javascript hooks implementing the given
+// faults, for use as the JavascriptHooks option of the mock CSI driver.
+func FaultInjectionHooks(faults []CSIFault) (map[string]string, error) {
+	hooks := map[string]string{
+		"globals": `faultCounters={}; console.log("fault injection hooks loaded");`,
+	}
+	for _, fault := range faults {
+		hook, ok := csiFaultHookNames[fault.Method]
+		if !ok {
+			return nil, fmt.Errorf("fault injection does not support CSI method %q", fault.Method)
+		}
+		if _, ok := hooks[hook]; ok {
+			return nil, fmt.Errorf("duplicate fault for CSI method %q", fault.Method)
+		}
+		if fault.ErrorCode == "" && fault.DelaySeconds == 0 {
+			return nil, fmt.Errorf("fault for CSI method %q injects neither an error nor a delay", fault.Method)
+		}
+
+		var script strings.Builder
+		fmt.Fprintf(&script, "faultCounters[%q] = (faultCounters[%q] || 0) + 1; console.log(%q, faultCounters[%q]);", fault.Method, fault.Method, fault.Method+" call:", fault.Method)
+		if fault.DelaySeconds > 0 {
+			// The hook environment has no sleep, so busy-wait.
+			fmt.Fprintf(&script, " var end = Date.now() + %d; while (Date.now() < end) {};", fault.DelaySeconds*1000)
+		}
+		switch {
+		case fault.ErrorCode == "":
+			script.WriteString(" OK;")
+		case fault.FailCalls > 0:
+			fmt.Fprintf(&script, " if (faultCounters[%q] <= %d) { %s; } else { OK; }", fault.Method, fault.FailCalls, fault.ErrorCode)
+		default:
+			fmt.Fprintf(&script, " %s;", fault.ErrorCode)
+		}
+		hooks[hook] = script.String()
+	}
+	return hooks, nil
+}
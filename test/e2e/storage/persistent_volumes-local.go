@@ -367,6 +367,38 @@ var _ = utils.SIGDescribe("PersistentVolumes-local ", func() {
 		})
 	})
 
+	ginkgo.Context("Local volume with WaitForFirstConsumer binding mode", func() {
+		var testVol *localTestVolume
+
+		ginkgo.BeforeEach(func() {
+			setupStorageClass(config, &waitMode)
+			// createLocalPVCsPVs verifies that the PVC stays Pending while
+			// no pod consumes it.
+			testVols := setupLocalVolumesPVCsPVs(config, DirectoryLocalVolumeType, config.node0, 1, waitMode)
+			testVol = testVols[0]
+		})
+
+		ginkgo.AfterEach(func() {
+			cleanupLocalVolumes(config, []*localTestVolume{testVol})
+			cleanupStorageClass(config)
+		})
+
+		ginkgo.It("should defer binding until a pod is scheduled and schedule it to the PV's node", func() {
+			ginkgo.By("Creating a pod with no node selection")
+			pod, err := createLocalPod(config, testVol, nil)
+			framework.ExpectNoError(err)
+
+			ginkgo.By("Verifying the pod was scheduled to the node of the PV's node affinity")
+			verifyLocalPod(config, testVol, pod, config.node0.Name)
+
+			ginkgo.By("Verifying the PVC bound once the pod was scheduled")
+			verifyLocalVolume(config, testVol)
+
+			ginkgo.By("Deleting the pod")
+			e2epod.DeletePodOrFail(config.client, config.ns, pod.Name)
+		})
+	})
+
 	ginkgo.Context("StatefulSet with pod affinity [Slow]", func() {
 		var testVols map[string][]*localTestVolume
 		const (
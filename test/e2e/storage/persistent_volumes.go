@@ -309,6 +309,58 @@ var _ = utils.SIGDescribe("PersistentVolumes", func() {
 				framework.Logf("Pod exited without failure; the volume has been recycled.")
 			})
 		})
+
+		// This Context isolates and tests the "Retain" reclaim behavior.
+		ginkgo.Context("when invoking the Retain reclaim policy", func() {
+			ginkgo.BeforeEach(func() {
+				pvConfig.ReclaimPolicy = v1.PersistentVolumeReclaimRetain
+				pv, pvc, err = e2epv.CreatePVPVC(c, pvConfig, pvcConfig, ns, false)
+				framework.ExpectNoError(err, "BeforeEach: Failed to create PV/PVC")
+				framework.ExpectNoError(e2epv.WaitOnPVandPVC(c, ns, pv, pvc), "BeforeEach: WaitOnPVandPVC failed")
+			})
+
+			ginkgo.AfterEach(func() {
+				framework.Logf("AfterEach: Cleaning up test resources.")
+				if errs := e2epv.PVPVCCleanup(c, ns, pv, pvc); len(errs) > 0 {
+					framework.Failf("AfterEach: Failed to delete PVC and/or PV. Errors: %v", utilerrors.NewAggregate(errs))
+				}
+			})
+
+			// A retained PV must survive deletion of its claim, keep its data, and stay
+			// "Released" (unavailable to new claims) until the claimRef is cleared by an
+			// admin. Once cleared, the PV becomes "Available" and binds to a new claim.
+			ginkgo.It("should retain the volume and its data until the claimRef is cleared", func() {
+				ginkgo.By("Writing to the volume.")
+				pod := e2epod.MakePod(ns, nil, []*v1.PersistentVolumeClaim{pvc}, true, "touch /mnt/volume1/SUCCESS && (id -G | grep -E '\\b777\\b')")
+				pod, err = c.CoreV1().Pods(ns).Create(context.TODO(), pod, metav1.CreateOptions{})
+				framework.ExpectNoError(err)
+				framework.ExpectNoError(e2epod.WaitForPodSuccessInNamespace(c, pod.Name, ns))
+				framework.ExpectNoError(e2epod.DeletePodWithWait(c, pod))
+
+				ginkgo.By("Deleting the claim")
+				framework.ExpectNoError(e2epv.DeletePVCandValidatePV(c, ns, pvc, pv, v1.VolumeReleased))
+
+				ginkgo.By("Verifying a new claim does not bind to the released PV")
+				pvc = e2epv.MakePersistentVolumeClaim(pvcConfig, ns)
+				pvc, err = e2epv.CreatePVC(c, ns, pvc)
+				framework.ExpectNoError(err)
+				err = e2epv.WaitForPersistentVolumeClaimPhase(v1.ClaimBound, c, ns, pvc.Name, 2*time.Second, 30*time.Second)
+				framework.ExpectError(err, "Released PV with a non-nil claimRef must not bind to a new PVC")
+
+				ginkgo.By("Clearing the PV's claimRef to make it Available again")
+				pv, err = c.CoreV1().PersistentVolumes().Get(context.TODO(), pv.Name, metav1.GetOptions{})
+				framework.ExpectNoError(err)
+				pv.Spec.ClaimRef = nil
+				pv, err = c.CoreV1().PersistentVolumes().Update(context.TODO(), pv, metav1.UpdateOptions{})
+				framework.ExpectNoError(err)
+
+				ginkgo.By("Verifying the new claim binds to the PV")
+				framework.ExpectNoError(e2epv.WaitForPersistentVolumeClaimPhase(v1.ClaimBound, c, ns, pvc.Name, 2*time.Second, 60*time.Second), "Failed to reach 'Bound' for PVC ", pvc.Name)
+
+				ginkgo.By("Verifying the data written before reclamation is still present")
+				framework.ExpectNoError(createWaitAndDeletePod(c, ns, pvc, "[ -e /mnt/volume1/SUCCESS ] && exit 0 || exit 1"))
+			})
+		})
 	})
 
 	ginkgo.Describe("Default StorageClass", func() {
@@ -378,6 +430,35 @@ var _ = utils.SIGDescribe("PersistentVolumes", func() {
 				e2estatefulset.WaitForRunningAndReady(c, 1, ss)
 			})
 		})
+
+		// This Context covers the "Delete" reclaim behavior of dynamically
+		// provisioned volumes: deleting the claim must delete the volume.
+		ginkgo.Context("when invoking the Delete reclaim policy", func() {
+			ginkgo.It("should delete a dynamically provisioned PV when the PVC is deleted", func() {
+				// Only run on providers with default storageclass
+				e2eskipper.SkipUnlessProviderIs("openstack", "gce", "gke", "vsphere", "azure")
+
+				ginkgo.By("Creating a PVC using the default StorageClass")
+				pvc := e2epv.MakePersistentVolumeClaim(e2epv.PersistentVolumeClaimConfig{}, ns)
+				pvc, err = e2epv.CreatePVC(c, ns, pvc)
+				framework.ExpectNoError(err)
+				defer func() {
+					framework.ExpectNoError(e2epv.DeletePersistentVolumeClaim(c, pvc.Name, ns), "Failed to delete PVC ", pvc.Name)
+				}()
+				framework.ExpectNoError(e2epv.WaitForPersistentVolumeClaimPhase(v1.ClaimBound, c, ns, pvc.Name, 2*time.Second, framework.ClaimProvisionTimeout), "Failed to reach 'Bound' for PVC ", pvc.Name)
+
+				ginkgo.By("Verifying the provisioned PV has the Delete reclaim policy")
+				pvc, err = c.CoreV1().PersistentVolumeClaims(ns).Get(context.TODO(), pvc.Name, metav1.GetOptions{})
+				framework.ExpectNoError(err)
+				pv, err := c.CoreV1().PersistentVolumes().Get(context.TODO(), pvc.Spec.VolumeName, metav1.GetOptions{})
+				framework.ExpectNoError(err)
+				framework.ExpectEqual(pv.Spec.PersistentVolumeReclaimPolicy, v1.PersistentVolumeReclaimDelete)
+
+				ginkgo.By("Deleting the claim to invoke the reclaim policy")
+				framework.ExpectNoError(e2epv.DeletePersistentVolumeClaim(c, pvc.Name, ns))
+				framework.ExpectNoError(e2epv.WaitForPersistentVolumeDeleted(c, pv.Name, 2*time.Second, 5*time.Minute), "PV ", pv.Name, " was not deleted after its claim was removed")
+			})
+		})
 	})
 })
 
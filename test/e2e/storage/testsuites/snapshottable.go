@@ -19,6 +19,7 @@ package testsuites
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo"
@@ -27,7 +28,9 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2epv "k8s.io/kubernetes/test/e2e/framework/pv"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
@@ -213,6 +216,118 @@ func (s *snapshottableTestSuite) DefineTests(driver TestDriver, pattern testpatt
 		framework.ExpectEqual(volumeSnapshotRef["name"], snapshot.GetName())
 		framework.ExpectEqual(volumeSnapshotRef["namespace"], snapshot.GetNamespace())
 	})
+
+	ginkgo.It("should honor the deletion policy of the bound VolumeSnapshotContent [Feature:VolumeSnapshotDataSource]", func() {
+		cs := f.ClientSet
+		dc := f.DynamicClient
+		SkipUnlessSnapshotCRDsPresent(cs)
+
+		config, driverCleanup := driver.PrepareTest(f)
+		defer func() {
+			err := tryFunc(driverCleanup)
+			framework.ExpectNoError(err, "while cleaning up driver")
+		}()
+
+		class := dDriver.GetDynamicProvisionStorageClass(config, "")
+		if class == nil {
+			e2eskipper.Skipf("Driver %q does not define Dynamic Provision StorageClass - skipping", driver.GetDriverInfo().Name)
+		}
+		testVolumeSizeRange := s.GetTestSuiteInfo().SupportedSizeRange
+		driverVolumeSizeRange := dDriver.GetDriverInfo().SupportedSizeRange
+		claimSize, err := getSizeRangesIntersection(testVolumeSizeRange, driverVolumeSizeRange)
+		framework.ExpectNoError(err, "determine intersection of test size range %+v and driver size range %+v", testVolumeSizeRange, driverVolumeSizeRange)
+		pvc := e2epv.MakePersistentVolumeClaim(e2epv.PersistentVolumeClaimConfig{
+			ClaimSize:        claimSize,
+			StorageClassName: &(class.Name),
+		}, config.Framework.Namespace.Name)
+
+		ginkgo.By("creating a StorageClass " + class.Name)
+		class, err = cs.StorageV1().StorageClasses().Create(context.TODO(), class, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		defer func() {
+			framework.ExpectNoError(cs.StorageV1().StorageClasses().Delete(context.TODO(), class.Name, metav1.DeleteOptions{}))
+		}()
+
+		ginkgo.By("creating a claim")
+		pvc, err = cs.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		defer func() {
+			err = cs.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(context.TODO(), pvc.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				framework.Failf("Error deleting claim %q. Error: %v", pvc.Name, err)
+			}
+		}()
+
+		ginkgo.By("starting a pod to use the claim")
+		command := "echo 'hello world' > /mnt/test/data"
+		pod := StartInPodWithVolume(cs, pvc.Namespace, pvc.Name, "pvc-snapshottable-tester", command, config.ClientNodeSelection)
+		defer StopPod(cs, pod)
+
+		err = e2epv.WaitForPersistentVolumeClaimPhase(v1.ClaimBound, cs, pvc.Namespace, pvc.Name, framework.Poll, framework.ClaimProvisionTimeout)
+		framework.ExpectNoError(err)
+
+		for _, policy := range []string{"Delete", "Retain"} {
+			ginkgo.By(fmt.Sprintf("creating a SnapshotClass with deletion policy %s", policy))
+			vsc := sDriver.GetSnapshotClass(config)
+			vsc.SetName(fmt.Sprintf("%s-%s", vsc.GetName(), strings.ToLower(policy)))
+			vsc.Object["deletionPolicy"] = policy
+			vsc, err = dc.Resource(SnapshotClassGVR).Create(context.TODO(), vsc, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+			defer func() {
+				framework.ExpectNoError(dc.Resource(SnapshotClassGVR).Delete(context.TODO(), vsc.GetName(), metav1.DeleteOptions{}))
+			}()
+
+			ginkgo.By("creating a snapshot")
+			snapshot := getSnapshot(pvc.Name, pvc.Namespace, vsc.GetName())
+			snapshot, err = dc.Resource(SnapshotGVR).Namespace(snapshot.GetNamespace()).Create(context.TODO(), snapshot, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+			err = WaitForSnapshotReady(dc, snapshot.GetNamespace(), snapshot.GetName(), framework.Poll, framework.SnapshotCreateTimeout)
+			framework.ExpectNoError(err)
+
+			snapshot, err = dc.Resource(SnapshotGVR).Namespace(snapshot.GetNamespace()).Get(context.TODO(), snapshot.GetName(), metav1.GetOptions{})
+			framework.ExpectNoError(err)
+			snapshotStatus := snapshot.Object["status"].(map[string]interface{})
+			snapshotContentName := snapshotStatus["boundVolumeSnapshotContentName"].(string)
+
+			ginkgo.By("deleting the snapshot")
+			err = dc.Resource(SnapshotGVR).Namespace(snapshot.GetNamespace()).Delete(context.TODO(), snapshot.GetName(), metav1.DeleteOptions{})
+			framework.ExpectNoError(err)
+
+			if policy == "Delete" {
+				ginkgo.By("waiting for the VolumeSnapshotContent to be deleted as well")
+				err = wait.PollImmediate(framework.Poll, framework.SnapshotDeleteTimeout, func() (bool, error) {
+					_, err := dc.Resource(SnapshotContentGVR).Get(context.TODO(), snapshotContentName, metav1.GetOptions{})
+					if apierrors.IsNotFound(err) {
+						return true, nil
+					}
+					return false, err
+				})
+				framework.ExpectNoError(err, "while waiting for VolumeSnapshotContent %s to be deleted", snapshotContentName)
+			} else {
+				ginkgo.By("checking that the VolumeSnapshotContent is retained")
+				// There is no condition to wait for, so give the snapshot
+				// controller some time to (wrongly) act on the content.
+				time.Sleep(30 * time.Second)
+				_, err = dc.Resource(SnapshotContentGVR).Get(context.TODO(), snapshotContentName, metav1.GetOptions{})
+				framework.ExpectNoError(err, "VolumeSnapshotContent %s should have been retained", snapshotContentName)
+
+				ginkgo.By("deleting the retained VolumeSnapshotContent")
+				framework.ExpectNoError(dc.Resource(SnapshotContentGVR).Delete(context.TODO(), snapshotContentName, metav1.DeleteOptions{}))
+			}
+		}
+	})
+}
+
+// SkipUnlessSnapshotCRDsPresent skips the test when the cluster does not
+// serve the snapshot CRDs. The CRDs and the snapshot controller are cluster
+// add-ons shipped with the external-snapshotter and deployed out of band (see
+// test/e2e/testing-manifests/storage-csi/external-snapshotter), so tests
+// check for them instead of installing them on the fly.
+func SkipUnlessSnapshotCRDsPresent(c clientset.Interface) {
+	_, err := c.Discovery().ServerResourcesForGroupVersion(snapshotAPIVersion)
+	if err != nil {
+		e2eskipper.Skipf("Cluster does not serve %s (deploy the external-snapshotter CRDs and controller first): %v", snapshotAPIVersion, err)
+	}
 }
 
 // WaitForSnapshotReady waits for a VolumeSnapshot to be ready to use or until timeout occurs, whichever comes first.
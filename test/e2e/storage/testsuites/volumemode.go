@@ -405,6 +405,43 @@ func (t *volumeModeTestSuite) DefineTests(driver TestDriver, pattern testpattern
 			gomega.Expect(path).NotTo(gomega.ContainSubstring(safeVolumePlugin), fmt.Sprintf("no %s volume should be symlinked into pod directory", volumePlugin))
 		}
 	})
+
+	ginkgo.It("should map the volume to a usable device path inside the pod", func() {
+		if pattern.VolMode != v1.PersistentVolumeBlock {
+			e2eskipper.Skipf("Test only covers volumeMode Block - skipping")
+		}
+		skipTestIfBlockNotSupported(driver)
+		init()
+		testVolumeSizeRange := t.GetTestSuiteInfo().SupportedSizeRange
+		l.VolumeResource = *CreateVolumeResource(driver, l.config, pattern, testVolumeSizeRange)
+		defer cleanup()
+
+		ginkgo.By("Creating pod")
+		var err error
+		podConfig := e2epod.Config{
+			NS:            l.ns.Name,
+			PVCs:          []*v1.PersistentVolumeClaim{l.Pvc},
+			SeLinuxLabel:  e2epv.SELinuxLabel,
+			NodeSelection: l.config.ClientNodeSelection,
+		}
+		pod, err := e2epod.MakeSecPod(&podConfig)
+		framework.ExpectNoError(err)
+
+		pod, err = l.cs.CoreV1().Pods(l.ns.Name).Create(context.TODO(), pod, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "Failed to create pod")
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(l.cs, pod), "Failed to delete pod")
+		}()
+
+		err = e2epod.WaitForPodNameRunningInNamespace(l.cs, pod.Name, pod.Namespace)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Checking that the volume shows up as a block device in the pod")
+		utils.CheckVolumeModeOfPath(f, pod, pattern.VolMode, "/mnt/volume1")
+
+		ginkgo.By("Checking that the device is readable and writable")
+		utils.CheckReadWriteToPath(f, pod, pattern.VolMode, "/mnt/volume1")
+	})
 }
 
 func generateConfigsForPreprovisionedPVTest(scName string, volBindMode storagev1.VolumeBindingMode,
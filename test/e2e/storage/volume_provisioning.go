@@ -743,6 +743,62 @@ var _ = utils.SIGDescribe("Dynamic Provisioning", func() {
 			framework.ExpectNoError(err)
 			framework.ExpectEqual(claim.Status.Phase, v1.ClaimPending)
 		})
+
+		ginkgo.It("should assign the default StorageClass to a claim created without a storageclass", func() {
+			e2eskipper.SkipUnlessProviderIs("openstack", "gce", "aws", "gke", "vsphere", "azure")
+			scName, scErr := e2epv.GetDefaultStorageClassName(c)
+			if scErr != nil {
+				framework.Failf(scErr.Error())
+			}
+
+			ginkgo.By("creating a claim with no storageclass")
+			claim := e2epv.MakePersistentVolumeClaim(e2epv.PersistentVolumeClaimConfig{
+				ClaimSize: "2Gi",
+			}, ns)
+			claim, err := c.CoreV1().PersistentVolumeClaims(ns).Create(context.TODO(), claim, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+			defer func() {
+				framework.ExpectNoError(e2epv.DeletePersistentVolumeClaim(c, claim.Name, ns))
+			}()
+
+			ginkgo.By("checking the claim was assigned the default storageclass by admission")
+			if claim.Spec.StorageClassName == nil {
+				framework.Failf("claim %s was not assigned a storageclass", claim.Name)
+			}
+			framework.ExpectEqual(*claim.Spec.StorageClassName, scName)
+		})
+
+		// Adding a second default storage class can be disruptive to other tests that depend on the default
+		ginkgo.It("should reject a claim created without a storageclass when there are multiple defaults [Serial] [Disruptive]", func() {
+			e2eskipper.SkipUnlessProviderIs("openstack", "gce", "aws", "gke", "vsphere", "azure")
+			scName, scErr := e2epv.GetDefaultStorageClassName(c)
+			if scErr != nil {
+				framework.Failf(scErr.Error())
+			}
+			verifyDefaultStorageClass(c, scName, true)
+
+			ginkgo.By("creating a second default StorageClass")
+			secondDefault := newStorageClass(testsuites.StorageClassTest{}, ns, "second-default")
+			secondDefault.Annotations = map[string]string{storageutil.IsDefaultStorageClassAnnotation: "true"}
+			secondDefault, err := c.StorageV1().StorageClasses().Create(context.TODO(), secondDefault, metav1.CreateOptions{})
+			framework.ExpectNoError(err)
+			defer func() {
+				framework.Logf("deleting storage class %s", secondDefault.Name)
+				framework.ExpectNoError(c.StorageV1().StorageClasses().Delete(context.TODO(), secondDefault.Name, metav1.DeleteOptions{}))
+			}()
+
+			ginkgo.By("creating a claim with no storageclass and expecting admission to reject it")
+			claim := e2epv.MakePersistentVolumeClaim(e2epv.PersistentVolumeClaimConfig{
+				ClaimSize: "2Gi",
+			}, ns)
+			claim, err = c.CoreV1().PersistentVolumeClaims(ns).Create(context.TODO(), claim, metav1.CreateOptions{})
+			if err == nil {
+				defer func() {
+					framework.ExpectNoError(e2epv.DeletePersistentVolumeClaim(c, claim.Name, ns))
+				}()
+			}
+			framework.ExpectError(err, "claim creation must fail while multiple default StorageClasses exist")
+		})
 	})
 
 	framework.KubeDescribe("GlusterDynamicProvisioner", func() {
@@ -48,9 +48,19 @@ func AfterSuiteActions() {
 			framework.Logf("Error gathering metrics: %v", err)
 		}
 	}
+	framework.SummarizeAPIResponsiveness()
 	if framework.TestContext.NodeKiller.Enabled {
 		close(framework.TestContext.NodeKiller.NodeKillerStopCh)
 	}
+	if framework.TestContext.Disruption.Enabled {
+		close(framework.TestContext.Disruption.DisruptionStopCh)
+	}
+	if framework.TestContext.ReportDir != "" && framework.TestContext.ArtifactsUpload != "" {
+		framework.Logf("Uploading artifacts from %s to %s", framework.TestContext.ReportDir, framework.TestContext.ArtifactsUpload)
+		if err := framework.UploadArtifacts(framework.TestContext.ReportDir, framework.TestContext.ArtifactsUpload); err != nil {
+			framework.Logf("Error uploading artifacts: %v", err)
+		}
+	}
 }
 
 func gatherTestSuiteMetrics() error {
@@ -59,9 +69,13 @@ func gatherTestSuiteMetrics() error {
 	if err != nil {
 		return fmt.Errorf("error loading client: %v", err)
 	}
+	config, err := framework.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading client config: %v", err)
+	}
 
 	// Grab metrics for apiserver, scheduler, controller-manager, kubelet (for non-kubemark case) and cluster autoscaler (optionally).
-	grabber, err := e2emetrics.NewMetricsGrabber(c, nil, !framework.ProviderIs("kubemark"), true, true, true, framework.TestContext.IncludeClusterAutoscalerMetrics)
+	grabber, err := e2emetrics.NewMetricsGrabber(c, nil, config, !framework.ProviderIs("kubemark"), true, true, true, framework.TestContext.IncludeClusterAutoscalerMetrics)
 	if err != nil {
 		return fmt.Errorf("failed to create MetricsGrabber: %v", err)
 	}
@@ -0,0 +1,181 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrades
+
+import (
+	"bytes"
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+
+	"github.com/onsi/ginkgo"
+)
+
+// ControllerRevisionUpgradeTest tests that the ControllerRevision history of
+// a DaemonSet, including owner references, survives a cluster upgrade.
+type ControllerRevisionUpgradeTest struct {
+	daemonSet *appsv1.DaemonSet
+	revision  *appsv1.ControllerRevision
+}
+
+// Name returns the tracking name of the test.
+func (ControllerRevisionUpgradeTest) Name() string {
+	return "[sig-apps] controllerrevision-upgrade"
+}
+
+// Setup creates a DaemonSet and waits for the controller to record its
+// ControllerRevision.
+func (t *ControllerRevisionUpgradeTest) Setup(f *framework.Framework) {
+	daemonSetName := "revision-ds"
+	labelSet := map[string]string{"ds-name": daemonSetName}
+
+	ns := f.Namespace
+
+	t.daemonSet = &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns.Name,
+			Name:      daemonSetName,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labelSet,
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelSet,
+				},
+				Spec: v1.PodSpec{
+					Tolerations: []v1.Toleration{
+						{Operator: v1.TolerationOpExists},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  "pause",
+							Image: imageutils.GetPauseImageName(),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ginkgo.By("Creating a DaemonSet")
+	var err error
+	if t.daemonSet, err = f.ClientSet.AppsV1().DaemonSets(ns.Name).Create(context.TODO(), t.daemonSet, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create test DaemonSet %s: %v", t.daemonSet.Name, err)
+	}
+
+	ginkgo.By("Waiting for the DaemonSet's ControllerRevision to be recorded")
+	err = wait.Poll(framework.Poll, framework.PodStartTimeout, func() (bool, error) {
+		revisions, err := t.listRevisions(f)
+		if err != nil {
+			return false, err
+		}
+		if len(revisions) != 1 {
+			return false, nil
+		}
+		t.revision = &revisions[0]
+		return true, nil
+	})
+	framework.ExpectNoError(err, "timed out waiting for a ControllerRevision owned by DaemonSet %s", t.daemonSet.Name)
+}
+
+// Test waits for the upgrade to complete, verifies the recorded revision
+// survived with its owner reference and data intact, and that a template
+// update still produces a new revision.
+func (t *ControllerRevisionUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade upgrades.UpgradeType) {
+	<-done
+
+	ginkgo.By("Verifying the ControllerRevision survived the upgrade")
+	revision, err := f.ClientSet.AppsV1().ControllerRevisions(t.revision.Namespace).Get(context.TODO(), t.revision.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(revision.UID, t.revision.UID)
+	framework.ExpectEqual(revision.Revision, t.revision.Revision)
+	if !bytes.Equal(revision.Data.Raw, t.revision.Data.Raw) {
+		framework.Failf("expected ControllerRevision %s data to be unchanged after upgrade", revision.Name)
+	}
+
+	ginkgo.By("Verifying the ControllerRevision is still owned by the DaemonSet")
+	controllerRef := metav1.GetControllerOf(revision)
+	if controllerRef == nil || controllerRef.UID != t.daemonSet.UID {
+		framework.Failf("expected ControllerRevision %s to be owned by DaemonSet %s, got owner %+v", revision.Name, t.daemonSet.Name, controllerRef)
+	}
+
+	ginkgo.By("Updating the DaemonSet template to record a new ControllerRevision")
+	daemonSet, err := f.ClientSet.AppsV1().DaemonSets(t.daemonSet.Namespace).Get(context.TODO(), t.daemonSet.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	daemonSet.Spec.Template.Spec.Containers[0].Env = []v1.EnvVar{{Name: "UPGRADED", Value: "true"}}
+	_, err = f.ClientSet.AppsV1().DaemonSets(daemonSet.Namespace).Update(context.TODO(), daemonSet, metav1.UpdateOptions{})
+	framework.ExpectNoError(err)
+
+	err = wait.Poll(framework.Poll, framework.PodStartTimeout, func() (bool, error) {
+		revisions, err := t.listRevisions(f)
+		if err != nil {
+			return false, err
+		}
+		return len(revisions) == 2, nil
+	})
+	framework.ExpectNoError(err, "timed out waiting for a second ControllerRevision owned by DaemonSet %s", t.daemonSet.Name)
+}
+
+// PreDowngrade re-reads the ControllerRevision through the newer apiserver
+// so PostDowngrade can compare what the older apiserver returns.
+func (t *ControllerRevisionUpgradeTest) PreDowngrade(f *framework.Framework) {
+	ginkgo.By("Reading the ControllerRevision through the newer apiserver")
+	revision, err := f.ClientSet.AppsV1().ControllerRevisions(t.revision.Namespace).Get(context.TODO(), t.revision.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	t.revision = revision
+}
+
+// PostDowngrade verifies the ControllerRevision written by the newer
+// apiserver remains readable and unchanged after the rollback.
+func (t *ControllerRevisionUpgradeTest) PostDowngrade(f *framework.Framework) {
+	ginkgo.By("Reading the ControllerRevision through the older apiserver")
+	revision, err := f.ClientSet.AppsV1().ControllerRevisions(t.revision.Namespace).Get(context.TODO(), t.revision.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(revision.Revision, t.revision.Revision)
+	if !bytes.Equal(revision.Data.Raw, t.revision.Data.Raw) {
+		framework.Failf("expected ControllerRevision %s data to be unchanged after downgrade", revision.Name)
+	}
+}
+
+// Teardown cleans up any remaining resources.
+func (t *ControllerRevisionUpgradeTest) Teardown(f *framework.Framework) {
+	// rely on the namespace deletion to clean up everything
+}
+
+// listRevisions returns the ControllerRevisions owned by the test's DaemonSet.
+func (t *ControllerRevisionUpgradeTest) listRevisions(f *framework.Framework) ([]appsv1.ControllerRevision, error) {
+	revisionList, err := f.ClientSet.AppsV1().ControllerRevisions(t.daemonSet.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var owned []appsv1.ControllerRevision
+	for i := range revisionList.Items {
+		controllerRef := metav1.GetControllerOf(&revisionList.Items[i])
+		if controllerRef != nil && controllerRef.UID == t.daemonSet.UID {
+			owned = append(owned, revisionList.Items[i])
+		}
+	}
+	return owned, nil
+}
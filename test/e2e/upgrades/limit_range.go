@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrades
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/kubernetes/test/e2e/framework"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+
+	"github.com/onsi/ginkgo"
+)
+
+// LimitRangeUpgradeTest tests that a LimitRange and its defaulting behavior
+// survive a cluster upgrade.
+type LimitRangeUpgradeTest struct {
+	limitRange *v1.LimitRange
+}
+
+// Name returns the tracking name of the test.
+func (LimitRangeUpgradeTest) Name() string {
+	return "[sig-scheduling] limitrange-upgrade"
+}
+
+// Setup creates a LimitRange with default requests and limits and verifies
+// that a pod created without resource requirements gets them applied.
+func (t *LimitRangeUpgradeTest) Setup(f *framework.Framework) {
+	ns := f.Namespace
+
+	t.limitRange = &v1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns.Name,
+			Name:      "upgrade-limit-range",
+		},
+		Spec: v1.LimitRangeSpec{
+			Limits: []v1.LimitRangeItem{
+				{
+					Type: v1.LimitTypeContainer,
+					Default: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("500m"),
+						v1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+					DefaultRequest: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("100m"),
+						v1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				},
+			},
+		},
+	}
+
+	ginkgo.By("Creating a LimitRange")
+	var err error
+	if t.limitRange, err = f.ClientSet.CoreV1().LimitRanges(ns.Name).Create(context.TODO(), t.limitRange, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create test LimitRange %s: %v", t.limitRange.Name, err)
+	}
+
+	ginkgo.By("Making sure the LimitRange defaults are applied")
+	t.testPod(f)
+}
+
+// Test waits for the upgrade to complete, verifies the LimitRange object is
+// unchanged and that it still defaults new pods.
+func (t *LimitRangeUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade UpgradeType) {
+	<-done
+
+	ginkgo.By("Verifying the LimitRange survived the upgrade")
+	limitRange, err := f.ClientSet.CoreV1().LimitRanges(t.limitRange.Namespace).Get(context.TODO(), t.limitRange.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(limitRange.UID, t.limitRange.UID)
+	if !apiequality.Semantic.DeepEqual(limitRange.Spec, t.limitRange.Spec) {
+		framework.Failf("expected LimitRange spec to be unchanged after upgrade, got %+v, want %+v", limitRange.Spec, t.limitRange.Spec)
+	}
+
+	ginkgo.By("Applying the LimitRange defaults after upgrade")
+	t.testPod(f)
+}
+
+// Teardown cleans up any remaining resources.
+func (t *LimitRangeUpgradeTest) Teardown(f *framework.Framework) {
+	// rely on the namespace deletion to clean up everything
+}
+
+// testPod creates a pod without resource requirements and verifies the
+// LimitRange defaults were applied to it.
+func (t *LimitRangeUpgradeTest) testPod(f *framework.Framework) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-limitrange-" + string(uuid.NewUUID()),
+			Namespace: t.limitRange.Namespace,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "pause",
+					Image: imageutils.GetPauseImageName(),
+				},
+			},
+		},
+	}
+
+	pod, err := f.ClientSet.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	framework.ExpectNoError(err)
+
+	item := t.limitRange.Spec.Limits[0]
+	for _, container := range pod.Spec.Containers {
+		if !apiequality.Semantic.DeepEqual(container.Resources.Requests, item.DefaultRequest) {
+			framework.Failf("expected container requests %+v to be defaulted to %+v", container.Resources.Requests, item.DefaultRequest)
+		}
+		if !apiequality.Semantic.DeepEqual(container.Resources.Limits, item.Default) {
+			framework.Failf("expected container limits %+v to be defaulted to %+v", container.Resources.Limits, item.Default)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+
+	storagev1 "k8s.io/api/storage/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	"k8s.io/kubernetes/test/e2e/upgrades"
+
+	"github.com/onsi/ginkgo"
+)
+
+// VolumeAttachmentUpgradeTest tests that a VolumeAttachment object survives
+// a cluster upgrade unchanged. The attachment references an attacher that
+// does not exist, so no component ever acts on it; the test only covers
+// persistence of the cluster-scoped object.
+type VolumeAttachmentUpgradeTest struct {
+	attachment *storagev1.VolumeAttachment
+	// preDowngradeAttachment is written through the newer apiserver during
+	// downgrade suites, to verify the older apiserver can still read it.
+	preDowngradeAttachment *storagev1.VolumeAttachment
+}
+
+// Name returns the tracking name of the test.
+func (VolumeAttachmentUpgradeTest) Name() string { return "[sig-storage] volume-attachment-upgrade" }
+
+// Setup creates a VolumeAttachment against a real node.
+func (t *VolumeAttachmentUpgradeTest) Setup(f *framework.Framework) {
+	node, err := e2enode.GetRandomReadySchedulableNode(f.ClientSet)
+	framework.ExpectNoError(err)
+
+	pvName := "upgrade-va-pv"
+	t.attachment = &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "upgrade-va-" + string(uuid.NewUUID()),
+		},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "upgrade.e2e.invalid",
+			NodeName: node.Name,
+			Source: storagev1.VolumeAttachmentSource{
+				PersistentVolumeName: &pvName,
+			},
+		},
+	}
+
+	ginkgo.By("Creating a VolumeAttachment")
+	if t.attachment, err = f.ClientSet.StorageV1().VolumeAttachments().Create(context.TODO(), t.attachment, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create test VolumeAttachment %s: %v", t.attachment.Name, err)
+	}
+}
+
+// Test waits for the upgrade to complete and verifies the VolumeAttachment
+// still exists with an unchanged spec.
+func (t *VolumeAttachmentUpgradeTest) Test(f *framework.Framework, done <-chan struct{}, upgrade upgrades.UpgradeType) {
+	<-done
+
+	ginkgo.By("Verifying the VolumeAttachment survived the upgrade")
+	attachment, err := f.ClientSet.StorageV1().VolumeAttachments().Get(context.TODO(), t.attachment.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(attachment.UID, t.attachment.UID)
+	if !apiequality.Semantic.DeepEqual(attachment.Spec, t.attachment.Spec) {
+		framework.Failf("expected VolumeAttachment spec to be unchanged after upgrade, got %+v, want %+v", attachment.Spec, t.attachment.Spec)
+	}
+}
+
+// PreDowngrade creates a second VolumeAttachment through the newer apiserver
+// just before the rollback starts.
+func (t *VolumeAttachmentUpgradeTest) PreDowngrade(f *framework.Framework) {
+	attachment := &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "downgrade-va-" + string(uuid.NewUUID()),
+		},
+		Spec: *t.attachment.Spec.DeepCopy(),
+	}
+
+	ginkgo.By("Creating a VolumeAttachment through the newer apiserver")
+	var err error
+	if t.preDowngradeAttachment, err = f.ClientSet.StorageV1().VolumeAttachments().Create(context.TODO(), attachment, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create pre-downgrade VolumeAttachment %s: %v", attachment.Name, err)
+	}
+}
+
+// PostDowngrade verifies the VolumeAttachment written by the newer apiserver
+// remains readable and unchanged after the rollback.
+func (t *VolumeAttachmentUpgradeTest) PostDowngrade(f *framework.Framework) {
+	ginkgo.By("Reading the pre-downgrade VolumeAttachment through the older apiserver")
+	attachment, err := f.ClientSet.StorageV1().VolumeAttachments().Get(context.TODO(), t.preDowngradeAttachment.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(attachment.UID, t.preDowngradeAttachment.UID)
+	if !apiequality.Semantic.DeepEqual(attachment.Spec, t.preDowngradeAttachment.Spec) {
+		framework.Failf("expected VolumeAttachment spec to be unchanged after downgrade, got %+v, want %+v", attachment.Spec, t.preDowngradeAttachment.Spec)
+	}
+}
+
+// Teardown deletes the cluster-scoped VolumeAttachments, which namespace
+// deletion does not cover.
+func (t *VolumeAttachmentUpgradeTest) Teardown(f *framework.Framework) {
+	attachments := []*storagev1.VolumeAttachment{t.attachment}
+	if t.preDowngradeAttachment != nil {
+		attachments = append(attachments, t.preDowngradeAttachment)
+	}
+	for _, attachment := range attachments {
+		err := f.ClientSet.StorageV1().VolumeAttachments().Delete(context.TODO(), attachment.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			framework.Failf("unable to delete test VolumeAttachment %s: %v", attachment.Name, err)
+		}
+	}
+}
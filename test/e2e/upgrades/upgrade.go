@@ -62,6 +62,23 @@ type Test interface {
 	Teardown(f *framework.Framework)
 }
 
+// DowngradeTest is an optional interface an upgrade test can implement to
+// take part in the explicit phases of a downgrade suite. PreDowngrade runs
+// after Setup while the cluster is still at the newer version, so tests can
+// write objects through the newer apiserver; PostDowngrade runs once the
+// rollback is complete and should assert those objects remain readable and
+// functional against the older apiserver.
+type DowngradeTest interface {
+	Test
+
+	// PreDowngrade is called after Setup, before the downgrade starts.
+	PreDowngrade(f *framework.Framework)
+
+	// PostDowngrade is called after Test has observed the completed
+	// downgrade.
+	PostDowngrade(f *framework.Framework)
+}
+
 // Skippable is an interface that an upgrade test can implement to be
 // able to indicate that it should be skipped.
 type Skippable interface {
@@ -249,12 +249,14 @@ var _ = framework.KubeDescribe("LocalStorageCapacityIsolationEviction [Slow] [Se
 				pod: diskConsumingPod("emptydir-disk-sizelimit", useOverLimit, &v1.VolumeSource{
 					EmptyDir: &v1.EmptyDirVolumeSource{SizeLimit: &sizeLimit},
 				}, v1.ResourceRequirements{}),
+				evictionMessage: fmt.Sprintf("Usage of EmptyDir volume %q exceeds the limit", volumeName),
 			},
 			{
 				evictionPriority: 1, // This pod should be evicted because of memory emptyDir usage violation
 				pod: diskConsumingPod("emptydir-memory-sizelimit", useOverLimit, &v1.VolumeSource{
 					EmptyDir: &v1.EmptyDirVolumeSource{Medium: "Memory", SizeLimit: &sizeLimit},
 				}, v1.ResourceRequirements{}),
+				evictionMessage: fmt.Sprintf("Usage of EmptyDir volume %q exceeds the limit", volumeName),
 			},
 			{
 				evictionPriority: 1, // This pod should cross the container limit by writing to its writable layer.
@@ -447,6 +449,9 @@ type podEvictSpec struct {
 	// The test ends when all pods other than p0 have been evicted
 	evictionPriority int
 	pod              *v1.Pod
+	// If non-empty, the Status.Message of the evicted pod must contain this
+	// string, e.g. to verify that the right emptyDir volume was blamed.
+	evictionMessage string
 }
 
 // runEvictionTest sets up a testing environment given the provided pods, and checks a few things:
@@ -631,6 +636,10 @@ func verifyEvictionOrdering(f *framework.Framework, testSpecs []podEvictSpec) er
 		if priorityPod.Status.Phase == v1.PodFailed {
 			framework.ExpectEqual(priorityPod.Status.Reason, eviction.Reason, "pod %s failed; expected Status.Reason to be %s, but got %s",
 				priorityPod.Name, eviction.Reason, priorityPod.Status.Reason)
+			if priorityPodSpec.evictionMessage != "" && !strings.Contains(priorityPod.Status.Message, priorityPodSpec.evictionMessage) {
+				framework.Failf("pod %s failed; expected Status.Message to contain %s, but got %s",
+					priorityPod.Name, priorityPodSpec.evictionMessage, priorityPod.Status.Message)
+			}
 		}
 
 		// EvictionPriority 0 pods should not fail
@@ -0,0 +1,287 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Media types a registry may return for a manifest request, per the Docker
+// registry HTTP API v2 and the OCI distribution spec.
+const (
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	dockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	ociIndexMediaType           = "application/vnd.oci.image.index.v1+json"
+	ociManifestMediaType        = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// imageDigests maps image indexes to the manifest digests VerifyImages
+// resolved their tags to.
+var imageDigests = map[int]string{}
+
+var manifestClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// GetE2EImageByDigest returns the fully qualified URI to an image pinned to
+// the digest its tag resolved to when VerifyImages ran, so that the image
+// cannot change under a running suite. It fails for images whose digest has
+// not been resolved.
+func GetE2EImageByDigest(image int) (string, error) {
+	digest, ok := imageDigests[image]
+	if !ok {
+		return "", fmt.Errorf("no digest resolved for image %q; VerifyImages has not run or did not cover it", GetE2EImage(image))
+	}
+	return fmt.Sprintf("%s/%s@%s", imageConfigs[image].registry, imageConfigs[image].name, digest), nil
+}
+
+// VerifyImages resolves the tag of every anonymously pullable test image to
+// a manifest digest, making the images available through
+// GetE2EImageByDigest, and checks that images published as manifest lists
+// provide a manifest for each of the given node architectures. It returns a
+// single error listing every image that could not be resolved or lacks an
+// architecture, so a suite can fail fast with a complete report instead of
+// hitting ErrImagePull in the middle of a test.
+func VerifyImages(architectures []string) error {
+	var problems []string
+	for _, index := range sortedImageIndexes() {
+		if isTestDataImage(index) {
+			continue
+		}
+		image := GetE2EImage(index)
+		digest, manifestArchs, err := fetchManifest(image)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", image, err))
+			continue
+		}
+		imageDigests[index] = digest
+		if len(manifestArchs) == 0 {
+			// A single-platform manifest carries no architecture; the
+			// architecture only lives in the image config blob. Resolving
+			// the digest already proved the image exists, so leave the
+			// architecture unchecked.
+			continue
+		}
+		for _, arch := range architectures {
+			if !manifestArchs[arch] {
+				problems = append(problems, fmt.Sprintf("%s: no manifest for architecture %q", image, arch))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d image problems:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// sortedImageIndexes returns the image indexes in a stable order so that
+// verification reports do not reshuffle between runs.
+func sortedImageIndexes() []int {
+	indexes := make([]int, 0, len(imageConfigs))
+	for index := range imageConfigs {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// fetchManifest requests the manifest for the given tagged image from its
+// registry and returns the manifest digest and, for manifest lists, the set
+// of architectures the list provides.
+func fetchManifest(image string) (string, map[string]bool, error) {
+	host, repository, tag, err := splitImageURL(image)
+	if err != nil {
+		return "", nil, err
+	}
+
+	url := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, tag)
+	body, contentType, digest, err := getManifest(url, "")
+	if err == errUnauthorized {
+		var token string
+		token, err = fetchRegistryToken(host, repository)
+		if err != nil {
+			return "", nil, err
+		}
+		body, contentType, digest, err = getManifest(url, token)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if digest == "" {
+		// Not all registries return Docker-Content-Digest; the digest is
+		// defined as the hash of the manifest bytes.
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+	}
+
+	archs := map[string]bool{}
+	if contentType == dockerManifestListMediaType || contentType == ociIndexMediaType {
+		var list struct {
+			Manifests []struct {
+				Platform struct {
+					Architecture string `json:"architecture"`
+				} `json:"platform"`
+			} `json:"manifests"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			return "", nil, fmt.Errorf("error parsing manifest list: %v", err)
+		}
+		for _, manifest := range list.Manifests {
+			archs[manifest.Platform.Architecture] = true
+		}
+	}
+	return digest, archs, nil
+}
+
+// errUnauthorized tells fetchManifest that the registry wants a token for
+// the request, which is how docker.io and quay.io serve anonymous pulls.
+var errUnauthorized = fmt.Errorf("registry requires authorization")
+
+// getManifest performs one manifest request, with the given bearer token if
+// any, and returns the manifest bytes, their media type and the digest the
+// registry reported.
+func getManifest(url, token string) ([]byte, string, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	req.Header.Set("Accept", strings.Join([]string{
+		dockerManifestListMediaType,
+		ociIndexMediaType,
+		dockerManifestMediaType,
+		ociManifestMediaType,
+	}, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := manifestClient.Do(req)
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized && token == "" {
+		return nil, "", "", errUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", fmt.Errorf("registry returned %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return body, resp.Header.Get("Content-Type"), resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// fetchRegistryToken obtains an anonymous pull token for the repository from
+// the token service the registry advertises in its WWW-Authenticate header.
+func fetchRegistryToken(host, repository string) (string, error) {
+	resp, err := manifestClient.Get(fmt.Sprintf("https://%s/v2/", host))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	realm, service, err := parseAuthHeader(resp.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s?scope=repository:%s:pull", realm, repository)
+	if service != "" {
+		url += "&service=" + service
+	}
+	resp, err = manifestClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token service returned %s", resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("error parsing token response: %v", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("token service response contained no token")
+}
+
+// parseAuthHeader extracts the realm and service from a WWW-Authenticate
+// header such as
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`.
+func parseAuthHeader(header string) (string, string, error) {
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", "", fmt.Errorf("unsupported WWW-Authenticate header %q", header)
+	}
+	var realm, service string
+	for _, part := range strings.Split(strings.TrimPrefix(header, "Bearer "), ",") {
+		keyValue := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		value := strings.Trim(keyValue[1], `"`)
+		switch keyValue[0] {
+		case "realm":
+			realm = value
+		case "service":
+			service = value
+		}
+	}
+	if realm == "" {
+		return "", "", fmt.Errorf("no realm in WWW-Authenticate header %q", header)
+	}
+	return realm, service, nil
+}
+
+// splitImageURL splits a tagged image URL into the registry API host, the
+// repository and the tag. docker.io is an alias that serves its API from
+// registry-1.docker.io.
+func splitImageURL(image string) (string, string, string, error) {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) != 2 {
+		return "", "", "", fmt.Errorf("image %q has no registry host", image)
+	}
+	host, remainder := parts[0], parts[1]
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+
+	colon := strings.LastIndex(remainder, ":")
+	if colon < 0 {
+		return "", "", "", fmt.Errorf("image %q has no tag", image)
+	}
+	return host, remainder[:colon], remainder[colon+1:], nil
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package image
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 
 	yaml "gopkg.in/yaml.v2"
@@ -27,21 +29,21 @@ import (
 
 // RegistryList holds public and private image registries
 type RegistryList struct {
-	GcAuthenticatedRegistry string `yaml:"gcAuthenticatedRegistry"`
-	DockerLibraryRegistry   string `yaml:"dockerLibraryRegistry"`
-	DockerGluster           string `yaml:"dockerGluster"`
-	E2eRegistry             string `yaml:"e2eRegistry"`
-	PromoterE2eRegistry     string `yaml:"promoterE2eRegistry"`
-	BuildImageRegistry      string `yaml:"buildImageRegistry"`
-	InvalidRegistry         string `yaml:"invalidRegistry"`
-	GcRegistry              string `yaml:"gcRegistry"`
-	GcrReleaseRegistry      string `yaml:"gcrReleaseRegistry"`
+	GcAuthenticatedRegistry string `yaml:"gcAuthenticatedRegistry" json:"gcAuthenticatedRegistry"`
+	DockerLibraryRegistry   string `yaml:"dockerLibraryRegistry" json:"dockerLibraryRegistry"`
+	DockerGluster           string `yaml:"dockerGluster" json:"dockerGluster"`
+	E2eRegistry             string `yaml:"e2eRegistry" json:"e2eRegistry"`
+	PromoterE2eRegistry     string `yaml:"promoterE2eRegistry" json:"promoterE2eRegistry"`
+	BuildImageRegistry      string `yaml:"buildImageRegistry" json:"buildImageRegistry"`
+	InvalidRegistry         string `yaml:"invalidRegistry" json:"invalidRegistry"`
+	GcRegistry              string `yaml:"gcRegistry" json:"gcRegistry"`
+	GcrReleaseRegistry      string `yaml:"gcrReleaseRegistry" json:"gcrReleaseRegistry"`
 	// TODO: The last consumer of this has been removed and it should be deprecated
-	GoogleContainerRegistry string `yaml:"googleContainerRegistry"`
-	PrivateRegistry         string `yaml:"privateRegistry"`
-	SampleRegistry          string `yaml:"sampleRegistry"`
-	QuayK8sCSI              string `yaml:"quayK8sCSI"`
-	QuayIncubator           string `yaml:"quayIncubator"`
+	GoogleContainerRegistry string `yaml:"googleContainerRegistry" json:"googleContainerRegistry"`
+	PrivateRegistry         string `yaml:"privateRegistry" json:"privateRegistry"`
+	SampleRegistry          string `yaml:"sampleRegistry" json:"sampleRegistry"`
+	QuayK8sCSI              string `yaml:"quayK8sCSI" json:"quayK8sCSI"`
+	QuayIncubator           string `yaml:"quayIncubator" json:"quayIncubator"`
 }
 
 // Config holds an images registry, name, and version
@@ -90,16 +92,58 @@ func initReg() RegistryList {
 		return registry
 	}
 
-	fileContent, err := ioutil.ReadFile(repoList)
+	if err := readRegistryList(repoList, &registry); err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// readRegistryList overlays the replacement registries from the given YAML or
+// JSON file onto list. Registries the file does not mention keep their
+// defaults, so a mirror only has to list the registries it actually serves.
+func readRegistryList(path string, list *RegistryList) error {
+	fileContent, err := ioutil.ReadFile(path)
 	if err != nil {
-		panic(fmt.Errorf("Error reading '%v' file contents: %v", repoList, err))
+		return fmt.Errorf("Error reading '%v' file contents: %v", path, err)
 	}
 
-	err = yaml.Unmarshal(fileContent, &registry)
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(fileContent, list)
+	} else {
+		err = yaml.Unmarshal(fileContent, list)
+	}
 	if err != nil {
-		panic(fmt.Errorf("Error unmarshalling '%v' YAML file: %v", repoList, err))
+		return fmt.Errorf("Error unmarshalling '%v' registry list file: %v", path, err)
 	}
-	return registry
+	return nil
+}
+
+// ReloadRegistryList re-reads the registry replacements from the given YAML or
+// JSON file and rebuilds the image configs with them. It backs the
+// --image-repo-list flag, which is read after this package's variables have
+// already been initialized; the KUBE_TEST_REPO_LIST environment variable is
+// the equivalent for callers that consume images before flag parsing.
+func ReloadRegistryList(path string) error {
+	list := initReg()
+	if err := readRegistryList(path, &list); err != nil {
+		return err
+	}
+	registry = list
+	dockerLibraryRegistry = list.DockerLibraryRegistry
+	dockerGluster = list.DockerGluster
+	e2eRegistry = list.E2eRegistry
+	promoterE2eRegistry = list.PromoterE2eRegistry
+	buildImageRegistry = list.BuildImageRegistry
+	gcAuthenticatedRegistry = list.GcAuthenticatedRegistry
+	gcRegistry = list.GcRegistry
+	gcrReleaseRegistry = list.GcrReleaseRegistry
+	invalidRegistry = list.InvalidRegistry
+	quayK8sCSI = list.QuayK8sCSI
+	quayIncubator = list.QuayIncubator
+	PrivateRegistry = list.PrivateRegistry
+	sampleRegistry = list.SampleRegistry
+	imageConfigs = initImageConfigs()
+	return nil
 }
 
 var (
@@ -252,6 +296,32 @@ func GetImageConfigs() map[int]Config {
 	return imageConfigs
 }
 
+// GetE2EImages returns the sorted list of fully qualified URIs of all images
+// the tests use that can be pulled anonymously, e.g. for pre-pulling them
+// onto nodes before a suite runs. Images that only exist to exercise pull
+// failures or registry authentication are left out.
+func GetE2EImages() []string {
+	images := make([]string, 0, len(imageConfigs))
+	for index, config := range imageConfigs {
+		if isTestDataImage(index) {
+			continue
+		}
+		images = append(images, config.GetE2EImage())
+	}
+	sort.Strings(images)
+	return images
+}
+
+// isTestDataImage reports whether the image exists only to exercise pull
+// failures or registry authentication, and so cannot be pulled anonymously.
+func isTestDataImage(index int) bool {
+	switch index {
+	case InvalidRegistryImage, AgnhostPrivate, AuthenticatedAlpine, AuthenticatedWindowsNanoServer:
+		return true
+	}
+	return false
+}
+
 // GetConfig returns the Config object for an image
 func GetConfig(image int) Config {
 	return imageConfigs[image]
@@ -272,6 +342,21 @@ func GetPauseImageName() string {
 	return GetE2EImage(Pause)
 }
 
+// windowsSupportedImages lists the images published as multi-OS manifest
+// lists with Windows variants, so the reference returned by GetE2EImage is
+// already OS-appropriate on Windows nodes.
+var windowsSupportedImages = map[int]bool{
+	Agnhost: true,
+	Pause:   true,
+}
+
+// IsImageSupportedOnWindows reports whether the image can run on Windows
+// nodes. Helpers building pods for Windows-only clusters should restrict
+// themselves to these images.
+func IsImageSupportedOnWindows(image int) bool {
+	return windowsSupportedImages[image]
+}
+
 // ReplaceRegistryInImageURL replaces the registry in the image URL with a custom one
 func ReplaceRegistryInImageURL(imageURL string) (string, error) {
 	parts := strings.Split(imageURL, "/")